@@ -3,19 +3,182 @@ package ji
 import (
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 )
 
+// Error 结构化错误，包含错误码、HTTP 状态码、原始错误与附加字段
+// 支持 errors.Is（错误码相同即视为同一类错误）与 errors.As/Unwrap
+type Error struct {
+	Code    int            `json:"code"`             // 业务错误码，对应 Resp.Code
+	Status  int            `json:"-"`                // HTTP 状态码，0 表示未指定
+	Message string         `json:"message"`          // 错误信息
+	Cause   error          `json:"-"`                // 原始错误
+	Fields  map[string]any `json:"fields,omitempty"` // 附加字段
+	stack   []uintptr      // 调用栈，通过 WithStack 捕获
+	args    []any          // 消息模板参数，仅 NewCodeError 创建的错误使用
+	catalog bool           // 消息是否来自错误信息目录
+	bare    bool           // Error() 是否省略“错误”前缀，用于保持库内原有错误的文本
+}
+
+// NewError 创建一个结构化错误
+// 参数:
+//   - code: 业务错误码
+//   - message: 错误信息
+//
+// 返回值:
+//   - *Error: 新创建的错误对象
+func NewError(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// NewCodeError 使用错误信息目录创建结构化错误，消息按全局默认语言生成，
+// 之后可通过 Localize 获取其他语言的消息
+func NewCodeError(code int, args ...any) *Error {
+	return &Error{Code: code, Message: Message(code, args...), args: args, catalog: true}
+}
+
+// newBareCodeError 与 NewCodeError 相同，但 Error() 不带“错误”前缀，
+// 用于库内原先直接返回消息文本的错误，保持其 Error() 结果不变
+func newBareCodeError(code int, args ...any) *Error {
+	e := NewCodeError(code, args...)
+	e.bare = true
+	return e
+}
+
+// Error 实现 error 接口，格式为 "错误: 信息: 原始错误"，前缀随全局默认语言变化
+func (e *Error) Error() string {
+	if e.bare {
+		if e.Cause != nil {
+			return fmt.Sprintf("%s: %s", e.Message, e.Cause.Error())
+		}
+		return e.Message
+	}
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s: %s", errorLabel(), e.Message, e.Cause.Error())
+	}
+	return fmt.Sprintf("%s: %s", errorLabel(), e.Message)
+}
+
+// Localize 获取指定语言下的错误信息（不含前缀与原始错误），
+// 仅 NewCodeError 创建的错误会被翻译，其他错误直接返回 Message
+func (e *Error) Localize(locale string) string {
+	if !e.catalog {
+		return e.Message
+	}
+	return Translate(locale, e.Code, e.args...)
+}
+
+// Unwrap 返回原始错误，供 errors.Is/As 使用
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is 错误码非 0 且相同时视为同一类错误，例如 errors.Is(err, NewError(404, ""))
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	if e.Code != 0 || t.Code != 0 {
+		return e.Code == t.Code
+	}
+	return e == t
+}
+
+// WithStatus 返回指定了 HTTP 状态码的副本
+func (e *Error) WithStatus(status int) *Error {
+	c := e.clone()
+	c.Status = status
+	return c
+}
+
+// Wrap 返回包装了原始错误的副本
+func (e *Error) Wrap(cause error) *Error {
+	c := e.clone()
+	c.Cause = cause
+	return c
+}
+
+// With 返回添加了附加字段的副本
+func (e *Error) With(key string, value any) *Error {
+	c := e.clone()
+	c.Fields = maps.Clone(e.Fields)
+	if c.Fields == nil {
+		c.Fields = make(map[string]any)
+	}
+	c.Fields[key] = value
+	return c
+}
+
+// WithStack 返回捕获了当前调用栈的副本
+func (e *Error) WithStack() *Error {
+	c := e.clone()
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	c.stack = pcs[:n]
+	return c
+}
+
+// Stack 返回捕获的调用栈，未调用 WithStack 时返回空字符串
+func (e *Error) Stack() string {
+	if len(e.stack) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		builder.WriteString(fmt.Sprintf("%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+	return builder.String()
+}
+
+// Format 实现 fmt.Formatter，%+v 会额外输出错误码、附加字段与调用栈
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		io.WriteString(s, e.Error())
+		fmt.Fprintf(s, "\ncode=%d", e.Code)
+		if e.Status != 0 {
+			fmt.Fprintf(s, " status=%d", e.Status)
+		}
+		for _, key := range slices.Sorted(maps.Keys(e.Fields)) {
+			fmt.Fprintf(s, " %s=%v", key, e.Fields[key])
+		}
+		if stack := e.Stack(); stack != "" {
+			io.WriteString(s, "\n"+stack)
+		}
+	case verb == 'v' || verb == 's':
+		io.WriteString(s, e.Error())
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// clone 复制错误对象，避免修改共享的错误定义
+func (e *Error) clone() *Error {
+	c := *e
+	return &c
+}
+
 // Miss 创建一个新的错误对象，包含拼接后的错误消息
 // 参数:
 //   - str: 要拼接的多个字符串
 //
 // 返回值:
-//   - error: 新创建的错误对象，包含拼接后的错误信息
+//   - error: 新创建的错误对象（*Error），包含拼接后的错误信息
 func Miss(str ...string) error {
 	// 如果没有传递任何参数，则返回一个空的错误
 	if len(str) == 0 {
-		return errors.New("未提供错误信息")
+		return errors.New(Message(CodeNoMessage))
 	}
 
 	// 使用 strings.Builder 拼接多个字符串，提高性能
@@ -29,5 +192,77 @@ func Miss(str ...string) error {
 	}
 
 	// 返回拼接后的错误信息
-	return fmt.Errorf("错误: %s", builder.String())
+	return &Error{Message: builder.String()}
+}
+
+// MergeErrors 合并多个错误为一个错误（errors.Join 语义）
+// nil 会被忽略，全部为 nil 时返回 nil；合并后的错误保留每个原始错误，可继续使用 errors.Is/As
+func MergeErrors(_errs ...error) error {
+	return errors.Join(_errs...)
+}
+
+// MergeErrorsUnique 合并多个错误，并去除错误信息相同的重复项（保留第一次出现的错误）
+func MergeErrorsUnique(_errs ...error) error {
+	return errors.Join(uniqueErrors(_errs)...)
+}
+
+// ErrorCollector 并发安全的错误收集器，零值即可使用，适用于从多个 goroutine 收集错误
+type ErrorCollector struct {
+	Unique bool // 是否去除错误信息相同的重复项
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// Add 添加错误，nil 会被忽略
+func (c *ErrorCollector) Add(errs ...error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, err := range errs {
+		if err != nil {
+			c.errs = append(c.errs, err)
+		}
+	}
+}
+
+// Len 获取已收集的错误数量（未去重）
+func (c *ErrorCollector) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.errs)
+}
+
+// Errors 获取已收集错误的副本
+func (c *ErrorCollector) Errors() []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Unique {
+		return uniqueErrors(c.errs)
+	}
+	return slices.Clone(c.errs)
+}
+
+// Err 将已收集的错误合并为一个错误，没有错误时返回 nil
+func (c *ErrorCollector) Err() error {
+	return errors.Join(c.Errors()...)
+}
+
+// uniqueErrors 去除 nil 以及错误信息相同的重复项
+func uniqueErrors(errs []error) []error {
+	seen := make(map[string]struct{}, len(errs))
+	result := make([]error, 0, len(errs))
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if _, ok := seen[err.Error()]; ok {
+			continue
+		}
+		seen[err.Error()] = struct{}{}
+		result = append(result, err)
+	}
+	return result
 }
@@ -0,0 +1,140 @@
+package ji
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	// 测试 Miss 兼容原有格式
+	if err := Miss("文件", "不存在"); err.Error() != "错误: 文件 不存在" {
+		t.Fatalf("期望错误信息为 错误: 文件 不存在，实际为 %v", err)
+	}
+
+	// 测试错误码匹配与包装
+	errNotFound := NewError(40400, "资源不存在").WithStatus(http.StatusNotFound)
+	err := fmt.Errorf("查询用户: %w", errNotFound.Wrap(io.EOF).With("id", 7))
+	if !errors.Is(err, errNotFound) || !errors.Is(err, io.EOF) {
+		t.Fatalf("期望 errors.Is 能匹配错误码与原始错误")
+	}
+	if errors.Is(err, NewError(50000, "内部错误")) {
+		t.Fatalf("期望不同错误码不匹配")
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Status != http.StatusNotFound || e.Fields["id"] != 7 {
+		t.Fatalf("期望 errors.As 获取到结构化错误，实际为 %v", e)
+	}
+	if e.Error() != "错误: 资源不存在: EOF" {
+		t.Fatalf("错误信息格式错误: %v", e.Error())
+	}
+	if errNotFound.Cause != nil || errNotFound.Fields != nil {
+		t.Fatalf("期望 Wrap/With 不修改原错误定义")
+	}
+
+	// 测试调用栈
+	detail := fmt.Sprintf("%+v", NewError(1, "stack").With("k", "v").WithStack())
+	if !strings.Contains(detail, "code=1 k=v") || !strings.Contains(detail, "TestError") {
+		t.Fatalf("期望 %%+v 输出错误码、字段与调用栈，实际为 %v", detail)
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	errA := errors.New("a")
+	errB := NewError(400, "b")
+
+	// 测试忽略 nil 并保留原始错误
+	if err := MergeErrors(nil, nil); err != nil {
+		t.Fatalf("期望全部为 nil 时返回 nil，实际为 %v", err)
+	}
+	err := MergeErrors(errA, nil, errB)
+	if !errors.Is(err, errA) || !errors.Is(err, NewError(400, "")) {
+		t.Fatalf("期望合并后的错误仍可使用 errors.Is 匹配")
+	}
+	if err.Error() != "a\n错误: b" {
+		t.Fatalf("合并后的错误信息错误: %q", err.Error())
+	}
+
+	// 测试去重
+	if err := MergeErrorsUnique(errA, errors.New("a"), errB); err.Error() != "a\n错误: b" {
+		t.Fatalf("期望去除重复的错误信息，实际为 %q", err.Error())
+	}
+
+	// 测试并发收集
+	collector := &ErrorCollector{Unique: true}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			collector.Add(fmt.Errorf("任务 %d 失败", i%2), nil)
+		}(i)
+	}
+	wg.Wait()
+	if collector.Len() != 10 || len(collector.Errors()) != 2 {
+		t.Fatalf("期望收集 10 个错误并去重为 2 个，实际为 %d / %v", collector.Len(), collector.Errors())
+	}
+	if (&ErrorCollector{}).Err() != nil {
+		t.Fatalf("期望没有错误时返回 nil")
+	}
+}
+
+func TestErrorCatalog(t *testing.T) {
+	// 测试库内错误保持原有的错误信息，同时可以翻译
+	_, err := SplitStringToIntSlice("1,,2")
+	var codeErr *Error
+	if err == nil || err.Error() != "输入的字符串在索引 1 处为空" {
+		t.Fatalf("期望返回原有的错误信息，实际为 %v", err)
+	}
+	if !errors.As(err, &codeErr) || codeErr.Localize(LocaleEnUS) != "input string is empty at index 1" {
+		t.Fatalf("期望库内错误可以翻译为英文，实际为 %v", err)
+	}
+	if _, err := FileSize("/not/exist.txt"); err == nil || err.Error() != "文件：/not/exist.txt不存在" {
+		t.Fatalf("期望返回原有的错误信息，实际为 %v", err)
+	}
+	codeErr = NewCodeError(CodeFileNotFound, "a.txt")
+	if codeErr.Localize(LocaleEnUS) != "file a.txt does not exist" || codeErr.Localize("en") != "file a.txt does not exist" {
+		t.Fatalf("期望翻译为英文，实际为 %v", codeErr.Localize(LocaleEnUS))
+	}
+	if codeErr.Localize("fr-FR") != "文件：a.txt不存在" {
+		t.Fatalf("期望未注册的语言回退到默认语言，实际为 %v", codeErr.Localize("fr-FR"))
+	}
+
+	// 测试注册自定义错误码与语言
+	RegisterMessages(LocaleZhCN, map[int]string{90001: "库存不足：%s"})
+	RegisterMessages("ja-JP", map[int]string{90001: "在庫不足：%s"})
+	if msg := Translate("ja-JP", 90001, "A"); msg != "在庫不足：A" {
+		t.Fatalf("期望返回日文消息，实际为 %v", msg)
+	}
+	if msg := Translate("ja-JP", CodeNotFound); msg != "资源不存在" {
+		t.Fatalf("期望缺失的翻译回退到默认语言，实际为 %v", msg)
+	}
+	if msg := Translate(LocaleEnUS, 99999); msg != "unknown error (99999)" {
+		t.Fatalf("期望未注册的错误码返回英文通用消息，实际为 %v", msg)
+	}
+	if msg := Translate(LocaleZhCN, 99999); msg != "未知错误（99999）" {
+		t.Fatalf("期望未注册的错误码返回中文通用消息，实际为 %v", msg)
+	}
+
+	// 测试根据 Accept-Language 选择语言
+	r, _ := http.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", "de;q=0.9, en;q=0.8, zh-CN;q=0.5")
+	if locale := LocaleFromRequest(r); locale != LocaleEnUS {
+		t.Fatalf("期望选择 en-US，实际为 %v", locale)
+	}
+	r.Header.Set("Accept-Language", "de")
+	if locale := LocaleFromRequest(r); locale != LocaleZhCN {
+		t.Fatalf("期望没有匹配时返回默认语言，实际为 %v", locale)
+	}
+
+	// 测试切换全局语言
+	SetLocale(LocaleEnUS)
+	defer SetLocale(LocaleZhCN)
+	if err := NewCodeError(CodeNotFound); err.Error() != "error: resource not found" {
+		t.Fatalf("期望返回英文错误信息，实际为 %v", err)
+	}
+}
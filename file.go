@@ -101,7 +101,7 @@ func FileExtension(filePath string) string {
 func FileSize(filePath string) (int64, error) {
 	fileInfo, exist := FileExist(filePath)
 	if !exist {
-		return 0, fmt.Errorf("文件：%s不存在", filePath)
+		return 0, newBareCodeError(CodeFileNotFound, filePath)
 	}
 	return fileInfo.Size(), nil
 }
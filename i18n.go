@@ -0,0 +1,222 @@
+package ji
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 内置语言
+const (
+	LocaleZhCN = "zh-CN" // 简体中文（默认）
+	LocaleEnUS = "en-US" // 美式英语
+)
+
+// 内置错误码
+const (
+	CodeInvalidParam   = 40000 // 参数错误
+	CodeEmptyElement   = 40001 // 字符串在指定索引处为空
+	CodeInvalidCursor  = 40002 // 分页游标无效
+	CodeUnauthorized   = 40100 // 未登录
+	CodeForbidden      = 40300 // 无权限
+	CodeNotFound       = 40400 // 资源不存在
+	CodeFileNotFound   = 40401 // 文件不存在
+	CodeInternal       = 50000 // 内部错误
+	CodeNoMessage      = 50001 // 未提供错误信息
+	CodeUnknown        = 50002 // 未注册的错误码，消息模板参数为错误码
+	CodeEmptyCursorKey = 50003 // 分页游标签名密钥为空
+)
+
+// catalog 错误信息目录，按语言与错误码保存消息模板
+var catalog = struct {
+	mu       sync.RWMutex
+	locale   string                    // 全局默认语言
+	labels   map[string]string         // 语言 -> 错误前缀
+	messages map[string]map[int]string // 语言 -> 错误码 -> 消息模板
+}{
+	locale: LocaleZhCN,
+	labels: map[string]string{
+		LocaleZhCN: "错误",
+		LocaleEnUS: "error",
+	},
+	messages: map[string]map[int]string{
+		LocaleZhCN: {
+			CodeInvalidParam:   "参数错误",
+			CodeEmptyElement:   "输入的字符串在索引 %d 处为空",
+			CodeInvalidCursor:  "分页游标无效或已被篡改",
+			CodeUnauthorized:   "未登录或登录已过期",
+			CodeForbidden:      "没有访问权限",
+			CodeNotFound:       "资源不存在",
+			CodeFileNotFound:   "文件：%s不存在",
+			CodeInternal:       "服务器内部错误",
+			CodeNoMessage:      "未提供错误信息",
+			CodeUnknown:        "未知错误（%d）",
+			CodeEmptyCursorKey: "分页游标签名密钥不能为空",
+		},
+		LocaleEnUS: {
+			CodeInvalidParam:   "invalid parameter",
+			CodeEmptyElement:   "input string is empty at index %d",
+			CodeInvalidCursor:  "invalid or tampered pagination cursor",
+			CodeUnauthorized:   "not logged in or session expired",
+			CodeForbidden:      "access denied",
+			CodeNotFound:       "resource not found",
+			CodeFileNotFound:   "file %s does not exist",
+			CodeInternal:       "internal server error",
+			CodeNoMessage:      "no error message provided",
+			CodeUnknown:        "unknown error (%d)",
+			CodeEmptyCursorKey: "pagination cursor signing key must not be empty",
+		},
+	},
+}
+
+// SetLocale 设置全局默认语言，例如 LocaleEnUS
+func SetLocale(locale string) {
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	catalog.locale = locale
+}
+
+// Locale 获取全局默认语言
+func Locale() string {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	return catalog.locale
+}
+
+// RegisterMessages 注册或覆盖某种语言下的错误信息，消息可以包含 fmt 格式化占位符
+// 参数:
+//   - locale: 语言，例如 "zh-CN"、"en-US"、"ja-JP"
+//   - messages: 错误码到消息模板的映射
+func RegisterMessages(locale string, messages map[int]string) {
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	if catalog.messages[locale] == nil {
+		catalog.messages[locale] = make(map[int]string, len(messages))
+	}
+	for code, message := range messages {
+		catalog.messages[locale][code] = message
+	}
+}
+
+// RegisterErrorLabel 注册某种语言下错误信息的前缀，例如 "エラー"
+func RegisterErrorLabel(locale, label string) {
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	catalog.labels[locale] = label
+}
+
+// Message 使用全局默认语言获取错误码对应的消息
+func Message(code int, args ...any) string {
+	return Translate(Locale(), code, args...)
+}
+
+// Translate 获取指定语言下错误码对应的消息
+// 查找顺序：指定语言、同一语种的其他语言（例如 en 匹配 en-US）、全局默认语言、简体中文；
+// 都未找到时返回 CodeUnknown 对应的包含错误码的通用消息
+func Translate(locale string, code int, args ...any) string {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	template, found := lookupMessage(locale, code)
+	if !found {
+		template, _ = lookupMessage(locale, CodeUnknown)
+		return fmt.Sprintf(template, code)
+	}
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// LocaleFromRequest 根据请求头 Accept-Language（支持 q 值）选择最合适的已注册语言，
+// 没有匹配时返回全局默认语言
+func LocaleFromRequest(r *http.Request) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{tag: tag, q: q})
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(b.q, a.q)
+	})
+
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	for _, c := range candidates {
+		if locale, found := matchLocale(c.tag); found {
+			return locale
+		}
+	}
+	return catalog.locale
+}
+
+// errorLabel 获取全局默认语言下的错误前缀
+func errorLabel() string {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	if label, found := catalog.labels[catalog.locale]; found {
+		return label
+	}
+	return catalog.labels[LocaleZhCN]
+}
+
+// lookupMessage 按回退顺序查找消息模板（调用方需持有读锁）
+func lookupMessage(locale string, code int) (string, bool) {
+	for _, candidate := range []string{locale, catalog.locale, LocaleZhCN} {
+		if matched, found := matchLocale(candidate); found {
+			if template, found := catalog.messages[matched][code]; found {
+				return template, true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchLocale 将语言标签匹配到已注册的语言，先精确匹配（不区分大小写），再按语种匹配（调用方需持有读锁）
+func matchLocale(tag string) (string, bool) {
+	if _, found := catalog.messages[tag]; found {
+		return tag, true
+	}
+	language, _, _ := strings.Cut(tag, "-")
+	var fallback string
+	for locale := range catalog.messages {
+		if strings.EqualFold(locale, tag) {
+			return locale, true
+		}
+		if registered, _, _ := strings.Cut(locale, "-"); strings.EqualFold(registered, language) {
+			// 同一语种可能注册了多种语言，取字典序最小的保证结果稳定
+			if fallback == "" || locale < fallback {
+				fallback = locale
+			}
+		}
+	}
+	return fallback, fallback != ""
+}
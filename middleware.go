@@ -0,0 +1,92 @@
+package ji
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// requestStartKey 请求开始时间在 context 中的键
+type requestStartKey struct{}
+
+// WithRequestStart 返回记录了请求开始时间的 context
+func WithRequestStart(ctx context.Context, start time.Time) context.Context {
+	return context.WithValue(ctx, requestStartKey{}, start)
+}
+
+// RequestStart 获取 context 中记录的请求开始时间
+func RequestStart(ctx context.Context) (time.Time, bool) {
+	start, ok := ctx.Value(requestStartKey{}).(time.Time)
+	return start, ok
+}
+
+// ExecTimeMiddleware 记录请求开始时间的中间件
+// 开始时间写入请求的 context，WriteResponseWithRequest 与 WriteResponse 会据此计算 Resp.ExecTime；
+// 同时在写入响应头时添加 X-Response-Time 与 Server-Timing 响应头
+func ExecTimeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		tw := &timingWriter{ResponseWriter: w, start: start}
+		next.ServeHTTP(tw, r.WithContext(WithRequestStart(r.Context(), start)))
+	})
+}
+
+// responseStart 沿 Unwrap 链查找 ExecTimeMiddleware 包装的 ResponseWriter，获取请求开始时间
+func responseStart(w http.ResponseWriter) (time.Time, bool) {
+	for {
+		switch v := w.(type) {
+		case *timingWriter:
+			return v.start, true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = v.Unwrap()
+		default:
+			return time.Time{}, false
+		}
+	}
+}
+
+// timingWriter 在写入响应头前添加耗时响应头
+type timingWriter struct {
+	http.ResponseWriter
+	start       time.Time
+	wroteHeader bool
+}
+
+// WriteHeader 添加耗时响应头后写入状态码
+func (tw *timingWriter) WriteHeader(statusCode int) {
+	if !tw.wroteHeader {
+		tw.wroteHeader = true
+		elapsed := float64(time.Since(tw.start).Microseconds()) / 1000
+		tw.Header().Set("X-Response-Time", fmt.Sprintf("%.3fms", elapsed))
+		tw.Header().Add("Server-Timing", fmt.Sprintf("app;dur=%.3f", elapsed))
+	}
+	tw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write 未显式调用 WriteHeader 时先写入 200 状态码
+func (tw *timingWriter) Write(b []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+// Flush 支持流式响应，见 FlushError
+func (tw *timingWriter) Flush() {
+	_ = tw.FlushError()
+}
+
+// FlushError 刷新响应，原始的 ResponseWriter 不支持刷新时返回 http.ErrNotSupported，
+// http.ResponseController 会优先使用该方法
+func (tw *timingWriter) FlushError() error {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	return http.NewResponseController(tw.ResponseWriter).Flush()
+}
+
+// Unwrap 返回原始的 ResponseWriter，供 http.ResponseController 使用
+func (tw *timingWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
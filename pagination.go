@@ -0,0 +1,266 @@
+package ji
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// 分页参数默认值
+const (
+	DefaultPageSize = 20  // 默认每页条数
+	MaxPageSize     = 100 // 默认每页最大条数
+)
+
+// ErrInvalidCursor 分页游标无效（格式错误或签名不匹配），可通过 errors.Is 判断
+var ErrInvalidCursor = NewCodeError(CodeInvalidCursor)
+
+// Cursor 分页游标内容，编码后对客户端不透明
+type Cursor struct {
+	Offset int    `json:"o,omitempty"` // 偏移量，用于偏移分页，由 PageParams.NextCursor 生成
+	After  string `json:"a,omitempty"` // 上一页最后一条记录的排序键，用于键集分页，由 PageParams.NextCursorAfter 生成
+}
+
+// CursorCodec 分页游标编解码器，使用 HMAC-SHA256 签名防止客户端篡改游标
+type CursorCodec struct {
+	key []byte
+}
+
+// NewCursorCodec 创建分页游标编解码器
+// 参数:
+//   - key: 签名密钥，不能为空
+//
+// 返回值:
+//   - *CursorCodec: 编解码器
+//   - error: 密钥为空时返回 CodeEmptyCursorKey 错误
+func NewCursorCodec(key []byte) (*CursorCodec, error) {
+	if len(key) == 0 {
+		return nil, NewCodeError(CodeEmptyCursorKey)
+	}
+	return &CursorCodec{key: append([]byte(nil), key...)}, nil
+}
+
+// Encode 将游标编码为 "内容.签名" 形式的 URL 安全字符串
+func (c *CursorCodec) Encode(cursor Cursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode 解码并校验游标，格式错误或签名不匹配时返回 ErrInvalidCursor
+func (c *CursorCodec) Decode(token string) (Cursor, error) {
+	var cursor Cursor
+	encodedPayload, encodedSignature, found := strings.Cut(token, ".")
+	if !found {
+		return cursor, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return cursor, ErrInvalidCursor.Wrap(err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return cursor, ErrInvalidCursor.Wrap(err)
+	}
+	if !hmac.Equal(signature, c.sign(payload)) {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return cursor, ErrInvalidCursor.Wrap(err)
+	}
+	if cursor.Offset < 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// sign 计算内容的 HMAC-SHA256 签名
+func (c *CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// PageParams 分页参数
+type PageParams struct {
+	Page   int     // 页码，从 1 开始；使用游标时根据偏移量推算
+	Size   int     // 每页条数
+	Cursor *Cursor // 解码后的游标，请求未携带游标时为 nil
+
+	codec *CursorCodec // 用于生成下一页游标
+}
+
+// Keyset 判断请求是否携带键集分页游标，此时应按 Cursor.After 查询下一页，Offset 为 0
+func (p PageParams) Keyset() bool {
+	return p.Cursor != nil && p.Cursor.After != ""
+}
+
+// Offset 获取偏移量，携带游标时使用游标中的偏移量
+func (p PageParams) Offset() int {
+	if p.Cursor != nil {
+		return p.Cursor.Offset
+	}
+	return (p.Page - 1) * p.Size
+}
+
+// Limit 获取每页条数，等同于 Size
+func (p PageParams) Limit() int {
+	return p.Size
+}
+
+// NextCursor 生成从指定偏移量开始的下一页游标，未配置 WithCursorCodec 时返回空字符串
+func (p PageParams) NextCursor(offset int) string {
+	return p.encodeCursor(Cursor{Offset: offset})
+}
+
+// NextCursorAfter 生成键集分页的下一页游标，key 为当前页最后一条记录的排序键，
+// 下一页请求可通过 Cursor.After 获取；未配置 WithCursorCodec 时返回空字符串
+// 例如：
+//
+//	if len(items) == params.Size {
+//		paged.NextCursor = params.NextCursorAfter(strconv.Itoa(items[len(items)-1].ID))
+//	}
+func (p PageParams) NextCursorAfter(key string) string {
+	return p.encodeCursor(Cursor{After: key})
+}
+
+// encodeCursor 编码游标，未配置 WithCursorCodec 或编码失败时返回空字符串
+func (p PageParams) encodeCursor(cursor Cursor) string {
+	if p.codec == nil {
+		return ""
+	}
+	token, err := p.codec.Encode(cursor)
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+// PageOption 分页参数解析选项
+type PageOption func(*pageConfig)
+
+// pageConfig 分页参数解析配置
+type pageConfig struct {
+	defaultSize int
+	maxSize     int
+	pageKey     string
+	sizeKey     string
+	cursorKey   string
+	codec       *CursorCodec
+}
+
+// WithPageSize 设置默认每页条数与每页最大条数
+func WithPageSize(defaultSize, maxSize int) PageOption {
+	return func(c *pageConfig) {
+		c.defaultSize = defaultSize
+		c.maxSize = maxSize
+	}
+}
+
+// WithPageKeys 设置查询参数名，默认为 page、page_size、cursor，传入空字符串表示保留默认值
+func WithPageKeys(page, size, cursor string) PageOption {
+	return func(c *pageConfig) {
+		if page != "" {
+			c.pageKey = page
+		}
+		if size != "" {
+			c.sizeKey = size
+		}
+		if cursor != "" {
+			c.cursorKey = cursor
+		}
+	}
+}
+
+// WithCursorCodec 启用游标分页，请求中的游标通过 codec 校验，PaginateSlice 与 NewPaged 会生成下一页的偏移量游标，
+// 键集分页的游标需要调用方通过 PageParams.NextCursorAfter 生成
+func WithCursorCodec(codec *CursorCodec) PageOption {
+	return func(c *pageConfig) {
+		c.codec = codec
+	}
+}
+
+// ParsePageParams 从请求的查询参数中解析分页参数
+// 页码小于 1 或无法解析时使用 1；每页条数小于 1 或无法解析时使用默认值，超过最大值时使用最大值；
+// 启用游标分页且请求携带游标时，优先使用游标中的偏移量
+// 参数:
+//   - r: HTTP 请求
+//   - opts: 可选配置，例如 WithPageSize、WithCursorCodec
+//
+// 返回值:
+//   - PageParams: 分页参数
+//   - error: 游标无效时返回 ErrInvalidCursor
+func ParsePageParams(r *http.Request, opts ...PageOption) (PageParams, error) {
+	config := pageConfig{
+		defaultSize: DefaultPageSize,
+		maxSize:     MaxPageSize,
+		pageKey:     "page",
+		sizeKey:     "page_size",
+		cursorKey:   "cursor",
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	config.maxSize = max(config.maxSize, 1)
+	config.defaultSize = min(max(config.defaultSize, 1), config.maxSize)
+
+	query := r.URL.Query()
+	params := PageParams{Page: 1, Size: config.defaultSize, codec: config.codec}
+	if size, err := strconv.Atoi(query.Get(config.sizeKey)); err == nil && size > 0 {
+		params.Size = min(size, config.maxSize)
+	}
+	if page, err := strconv.Atoi(query.Get(config.pageKey)); err == nil && page > 0 {
+		// 避免计算偏移量时溢出
+		params.Page = min(page, math.MaxInt/params.Size)
+	}
+
+	if token := query.Get(config.cursorKey); token != "" && config.codec != nil {
+		cursor, err := config.codec.Decode(token)
+		if err != nil {
+			return params, err
+		}
+		params.Cursor = &cursor
+		params.Page = cursor.Offset/params.Size + 1
+	}
+	return params, nil
+}
+
+// NewPaged 根据分页参数创建分页数据，还有下一页且启用了游标分页时生成偏移量游标 NextCursor；
+// 请求携带键集分页游标时（见 PageParams.Keyset）偏移量无意义，不会生成 NextCursor，
+// 调用方需根据当前页最后一条记录通过 PageParams.NextCursorAfter 设置
+// 参数:
+//   - items: 当前页的数据，nil 会被转换为空切片
+//   - total: 总条数
+//   - params: ParsePageParams 解析出的分页参数
+func NewPaged[T any](items []T, total int64, params PageParams) Paged[T] {
+	if items == nil {
+		items = []T{}
+	}
+	paged := Paged[T]{Items: items, Total: total, Page: params.Page, Size: params.Size}
+	if params.Keyset() {
+		return paged
+	}
+	if next := params.Offset() + len(items); int64(next) < total {
+		paged.NextCursor = params.NextCursor(next)
+	}
+	return paged
+}
+
+// PaginateSlice 对内存中的切片分页，支持页码与偏移量游标，偏移量超出范围时返回空页；
+// 切片中的数据没有排序键，请求携带键集分页游标时无法定位下一页，同样返回空页
+func PaginateSlice[T any](items []T, params PageParams) Paged[T] {
+	if params.Keyset() {
+		return NewPaged([]T{}, int64(len(items)), params)
+	}
+	start := min(max(params.Offset(), 0), len(items))
+	end := min(start+max(params.Size, 0), len(items))
+	return NewPaged(items[start:end:end], int64(len(items)), params)
+}
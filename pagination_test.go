@@ -0,0 +1,184 @@
+package ji
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParsePageParams(t *testing.T) {
+	// 测试页码与每页条数的默认值与截断
+	cases := []struct {
+		query string
+		page  int
+		size  int
+	}{
+		{"", 1, DefaultPageSize},
+		{"page=3&page_size=10", 3, 10},
+		{"page=0&page_size=-1", 1, DefaultPageSize},
+		{"page=abc&page_size=1000", 1, MaxPageSize},
+	}
+	for _, c := range cases {
+		params, err := ParsePageParams(httptest.NewRequest(http.MethodGet, "/?"+c.query, nil))
+		if err != nil {
+			t.Fatalf("%q: 解析分页参数失败: %v", c.query, err)
+		}
+		if params.Page != c.page || params.Size != c.size {
+			t.Fatalf("%q: 期望 page=%d size=%d，实际为 page=%d size=%d", c.query, c.page, c.size, params.Page, params.Size)
+		}
+	}
+
+	// 测试自定义参数名与条数限制
+	request := httptest.NewRequest(http.MethodGet, "/?p=2&limit=80", nil)
+	params, _ := ParsePageParams(request, WithPageKeys("p", "limit", ""), WithPageSize(10, 50))
+	if params.Page != 2 || params.Size != 50 || params.Offset() != 50 {
+		t.Fatalf("期望 page=2 size=50 offset=50，实际为 %+v", params)
+	}
+}
+
+func TestCursorCodec(t *testing.T) {
+	if _, err := NewCursorCodec(nil); !errors.Is(err, NewError(CodeEmptyCursorKey, "")) {
+		t.Fatalf("期望空密钥返回 CodeEmptyCursorKey 错误，实际为 %v", err)
+	}
+	codec, _ := NewCursorCodec([]byte("secret"))
+
+	// 测试编码与解码
+	token, err := codec.Encode(Cursor{Offset: 40, After: "id-40"})
+	if err != nil {
+		t.Fatalf("编码游标失败: %v", err)
+	}
+	cursor, err := codec.Decode(token)
+	if err != nil || cursor.Offset != 40 || cursor.After != "id-40" {
+		t.Fatalf("期望解码出原始游标，实际为 %+v, %v", cursor, err)
+	}
+
+	// 测试篡改与其他密钥签名的游标
+	other, _ := NewCursorCodec([]byte("other"))
+	forged, _ := other.Encode(Cursor{Offset: 40})
+	for _, bad := range []string{"", "abc", token[:len(token)-2], forged, strings.Replace(token, ".", "x.", 1)} {
+		if _, err := codec.Decode(bad); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("%q: 期望返回 ErrInvalidCursor，实际为 %v", bad, err)
+		}
+	}
+
+	// 测试无效游标的错误响应
+	request := httptest.NewRequest(http.MethodGet, "/?cursor=abc", nil)
+	if _, err := ParsePageParams(request, WithCursorCodec(codec)); err == nil {
+		t.Fatal("期望无效游标返回错误")
+	} else if status, resp := ErrorResponse(err, LocaleZhCN); status != http.StatusBadRequest || resp.Code != CodeInvalidCursor {
+		t.Fatalf("期望 400 与错误码 %d，实际为 %d 与 %d", CodeInvalidCursor, status, resp.Code)
+	}
+}
+
+func TestPaginateSlice(t *testing.T) {
+	items := make([]int, 45)
+	for i := range items {
+		items[i] = i
+	}
+	codec, _ := NewCursorCodec([]byte("secret"))
+
+	// 测试按页码分页
+	params, _ := ParsePageParams(httptest.NewRequest(http.MethodGet, "/?page=3&page_size=20", nil))
+	paged := PaginateSlice(items, params)
+	if len(paged.Items) != 5 || paged.Items[0] != 40 || paged.Total != 45 || paged.NextCursor != "" {
+		t.Fatalf("期望第 3 页包含 40-44 且无游标，实际为 %+v", paged)
+	}
+	params, _ = ParsePageParams(httptest.NewRequest(http.MethodGet, "/?page=9", nil))
+	if paged := PaginateSlice(items, params); len(paged.Items) != 0 || paged.Items == nil {
+		t.Fatalf("期望超出范围时返回空页，实际为 %+v", paged)
+	}
+
+	// 测试沿游标翻页直到最后一页
+	var collected []int
+	query := "/?page_size=20"
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("游标翻页未结束")
+		}
+		params, err := ParsePageParams(httptest.NewRequest(http.MethodGet, query, nil), WithCursorCodec(codec))
+		if err != nil {
+			t.Fatalf("解析分页参数失败: %v", err)
+		}
+		paged := PaginateSlice(items, params)
+		collected = append(collected, paged.Items...)
+		if paged.NextCursor == "" {
+			break
+		}
+		query = "/?page_size=20&cursor=" + paged.NextCursor
+	}
+	if len(collected) != 45 || collected[44] != 44 {
+		t.Fatalf("期望翻页获取全部 45 条数据，实际为 %d 条", len(collected))
+	}
+
+	// 测试键集分页游标
+	params, _ = ParsePageParams(httptest.NewRequest(http.MethodGet, "/?page_size=20", nil), WithCursorCodec(codec))
+	if params.Cursor != nil {
+		t.Fatalf("期望首页没有游标，实际为 %+v", params.Cursor)
+	}
+	query = "/?page_size=20&cursor=" + params.NextCursorAfter("id-19")
+	params, err := ParsePageParams(httptest.NewRequest(http.MethodGet, query, nil), WithCursorCodec(codec))
+	if err != nil || params.Cursor == nil || params.Cursor.After != "id-19" {
+		t.Fatalf("期望解析出键集游标 id-19，实际为 %+v, %v", params.Cursor, err)
+	}
+	if next := (PageParams{}).NextCursorAfter("id-19"); next != "" {
+		t.Fatalf("期望未配置编解码器时不生成游标，实际为 %q", next)
+	}
+	if paged := PaginateSlice(items, params); len(paged.Items) != 0 || paged.NextCursor != "" {
+		t.Fatalf("期望键集游标对切片分页返回空页，实际为 %+v", paged)
+	}
+}
+
+func TestKeysetPagination(t *testing.T) {
+	codec, _ := NewCursorCodec([]byte("secret"))
+	ids := make([]int, 45)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+
+	// query 模拟数据库查询：WHERE id > after ORDER BY id LIMIT size
+	query := func(params PageParams) []int {
+		after := 0
+		if params.Keyset() {
+			after, _ = strconv.Atoi(params.Cursor.After)
+		}
+		var page []int
+		for _, id := range ids {
+			if id > after && len(page) < params.Limit() {
+				page = append(page, id)
+			}
+		}
+		return page
+	}
+
+	// 测试沿键集游标翻页直到最后一页
+	var collected []int
+	target := "/?page_size=20"
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("键集游标翻页未结束")
+		}
+		params, err := ParsePageParams(httptest.NewRequest(http.MethodGet, target, nil), WithCursorCodec(codec))
+		if err != nil {
+			t.Fatalf("解析分页参数失败: %v", err)
+		}
+		items := query(params)
+		paged := NewPaged(items, int64(len(ids)), params)
+		if pages > 0 && paged.NextCursor != "" {
+			t.Fatalf("期望键集分页时 NewPaged 不生成偏移量游标，实际为 %q", paged.NextCursor)
+		}
+		if len(items) == params.Size {
+			paged.NextCursor = params.NextCursorAfter(strconv.Itoa(items[len(items)-1]))
+		}
+		collected = append(collected, paged.Items...)
+		if paged.NextCursor == "" {
+			break
+		}
+		target = "/?page_size=20&cursor=" + paged.NextCursor
+	}
+	if len(collected) != 45 || collected[0] != 1 || collected[44] != 45 {
+		t.Fatalf("期望翻页获取全部 45 条数据，实际为 %v", collected)
+	}
+}
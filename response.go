@@ -1,10 +1,17 @@
 package ji
 
 import (
+	"bytes"
+	"compress/flate"
 	"compress/gzip"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,52 +21,366 @@ type Resp struct {
 	Status          bool   `json:"status"`                    // 请求处理状态，true表示成功，false表示失败
 	Message         string `json:"message,omitempty"`         // 返回的提示信息，若无可省略
 	Data            any    `json:"data,omitempty"`            // 返回的实际数据，若为空则不返回该字段
-	ExecTime        int64  `json:"exec_time,omitempty"`       // 请求处理时间，单位：毫秒
+	ExecTime        int64  `json:"exec_time,omitempty"`       // 请求处理时间，单位：毫秒（ExecTimeInMicro 为 true 时为微秒）
 	IncludeExecTime bool   `json:"-"`                         // 控制是否添加执行时间字段（用于内部控制，不出现在 JSON 输出中）
+	ExecTimeInMicro bool   `json:"-"`                         // 执行时间使用微秒为单位（用于内部控制，不出现在 JSON 输出中）
 	HTMLIsEscaped   bool   `json:"html_is_escaped,omitempty"` // 是否转义HTML
 }
 
+// TypedResp 带类型参数的响应结构体，便于 OpenAPI 生成与客户端 SDK 推断 Data 的类型
+// 序列化时先通过 ToResp 转换为 Resp，因此 JSON 结果与相同数据的 Resp 完全一致
+type TypedResp[T any] struct {
+	Code            int    `json:"code"`                      // 接口返回的状态码
+	Status          bool   `json:"status"`                    // 请求处理状态，true表示成功，false表示失败
+	Message         string `json:"message,omitempty"`         // 返回的提示信息，若无可省略
+	Data            T      `json:"data,omitempty"`            // 返回的实际数据
+	ExecTime        int64  `json:"exec_time,omitempty"`       // 请求处理时间，单位：毫秒（ExecTimeInMicro 为 true 时为微秒）
+	IncludeExecTime bool   `json:"-"`                         // 控制是否添加执行时间字段
+	ExecTimeInMicro bool   `json:"-"`                         // 执行时间使用微秒为单位
+	HTMLIsEscaped   bool   `json:"html_is_escaped,omitempty"` // 是否转义HTML
+	omitData        bool   // 是否省略 Data，Fail 创建的响应没有数据
+}
+
+// Paged 分页数据
+type Paged[T any] struct {
+	Items      []T    `json:"items"`                 // 当前页的数据
+	Total      int64  `json:"total"`                 // 总条数
+	Page       int    `json:"page"`                  // 当前页码，从 1 开始
+	Size       int    `json:"size"`                  // 每页条数
+	NextCursor string `json:"next_cursor,omitempty"` // 下一页游标，没有下一页或未启用游标分页时为空
+}
+
+// OK 创建成功响应
+func OK[T any](data T) TypedResp[T] {
+	return TypedResp[T]{Code: 0, Status: true, Data: data}
+}
+
+// Fail 创建失败响应，T 为成功时 Data 的类型，序列化时省略 data 字段
+func Fail[T any](code int, message string) TypedResp[T] {
+	return TypedResp[T]{Code: code, Status: false, Message: message, omitData: true}
+}
+
+// Page 创建分页成功响应，items 为 nil 时序列化为空数组
+func Page[T any](items []T, total int64, page, size int) TypedResp[Paged[T]] {
+	if items == nil {
+		items = []T{}
+	}
+	return OK(Paged[T]{Items: items, Total: total, Page: page, Size: size})
+}
+
+// ToResp 转换为 Resp，Fail 创建的响应 Data 为 nil
+func (r TypedResp[T]) ToResp() Resp {
+	resp := Resp{
+		Code:            r.Code,
+		Status:          r.Status,
+		Message:         r.Message,
+		ExecTime:        r.ExecTime,
+		IncludeExecTime: r.IncludeExecTime,
+		ExecTimeInMicro: r.ExecTimeInMicro,
+		HTMLIsEscaped:   r.HTMLIsEscaped,
+	}
+	if !r.omitData {
+		resp.Data = r.Data
+	}
+	return resp
+}
+
+// MarshalJSON 通过 ToResp 序列化，保证与 Resp 的 JSON 结果一致
+func (r TypedResp[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.ToResp())
+}
+
+// WriteTypedResponse 写入带类型参数的响应，见 WriteResponseWithRequest
+func WriteTypedResponse[T any](w http.ResponseWriter, r *http.Request, statusCode int, resp TypedResp[T]) {
+	WriteResponseWithRequest(w, r, statusCode, resp.ToResp())
+}
+
+// CompressMinSize 启用压缩的最小响应体大小，小于该值的响应不压缩
+var CompressMinSize = 1 * KB
+
 // WriteResponse 用于写入 HTTP 响应
+// 该函数无法获取请求，需要根据 Accept-Encoding 协商压缩时请使用 WriteResponseWithRequest；
+// IncludeExecTime 为 true 时，执行时间从 ExecTimeMiddleware 记录的请求开始时间算起，
+// w 未经过 ExecTimeMiddleware 包装时无法获取开始时间，不会写入执行时间
 func WriteResponse(w http.ResponseWriter, statusCode int, resp Resp) {
-	// 获取请求开始时间，用于性能监控
-	start := time.Now()
+	if resp.IncludeExecTime {
+		if start, ok := responseStart(w); ok {
+			resp.setExecTime(start)
+		}
+	}
 
-	// 设置响应头
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+	// 兼容旧行为：调用方在响应头中设置了 Accept-Encoding 时使用 Gzip 压缩
+	encoding := ""
+	if strings.Contains(w.Header().Get("Accept-Encoding"), "gzip") {
+		encoding = "gzip"
+	}
+	writeJSON(w, statusCode, resp, encoding, 0)
+}
 
-	// 判断是否需要包含执行时间
+// WriteResponseWithRequest 根据请求写入 HTTP 响应
+// 按请求头 Accept-Encoding（支持 q 值）协商 gzip 或 deflate 压缩，
+// 响应体小于 CompressMinSize 时不压缩，并设置 Vary: Accept-Encoding；
+// IncludeExecTime 为 true 时，执行时间从 ExecTimeMiddleware 记录的请求开始时间算起
+func WriteResponseWithRequest(w http.ResponseWriter, r *http.Request, statusCode int, resp Resp) {
 	if resp.IncludeExecTime {
-		// 获取请求处理时间，并添加到响应体
-		resp.ExecTime = time.Since(start).Milliseconds()
+		start, ok := RequestStart(r.Context())
+		if !ok {
+			start = time.Now()
+		}
+		resp.setExecTime(start)
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+	writeJSON(w, statusCode, resp, negotiateEncoding(r.Header.Get("Accept-Encoding")), CompressMinSize)
+}
+
+// setExecTime 根据请求开始时间设置执行时间，ExecTimeInMicro 为 true 时使用微秒
+func (r *Resp) setExecTime(start time.Time) {
+	elapsed := time.Since(start)
+	r.ExecTime = elapsed.Milliseconds()
+	if r.ExecTimeInMicro {
+		r.ExecTime = elapsed.Microseconds()
 	}
+}
 
+// writeJSON 编码响应体并按指定的编码方式写入，响应头在 WriteHeader 之前全部设置完毕
+func writeJSON(w http.ResponseWriter, statusCode int, resp Resp, encoding string, minSize int) {
 	// 根据 HTMLIsEscaped 字段判断是否需要转义 HTML
 	escapeHTML := !resp.HTMLIsEscaped // 默认为 true，表示需要转义 HTML
 
-	// 判断客户端是否支持 Gzip 压缩
-	if strings.Contains(w.Header().Get("Accept-Encoding"), "gzip") {
-		// 启用 Gzip 压缩
-		w.Header().Set("Content-Encoding", "gzip")
-		gz := gzip.NewWriter(w)
-		defer gz.Close()
-
-		// 使用 json.NewEncoder 直接写入响应体
-		encoder := json.NewEncoder(gz)
-		encoder.SetEscapeHTML(escapeHTML) // 设置 HTML 转义
-		if err := encoder.Encode(resp); err != nil {
-			// 处理序列化错误
-			http.Error(w, "JSON 编码错误", http.StatusInternalServerError)
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	encoder.SetEscapeHTML(escapeHTML) // 设置 HTML 转义
+	if err := encoder.Encode(resp); err != nil {
+		// 处理序列化错误
+		http.Error(w, "JSON 编码错误", http.StatusInternalServerError)
+		return
+	}
+
+	payload := body.Bytes()
+	if encoding != "" && body.Len() >= minSize {
+		compressed, err := compress(payload, encoding)
+		if err != nil {
+			http.Error(w, "响应压缩错误", http.StatusInternalServerError)
 			return
 		}
-	} else {
-		// 如果不支持 Gzip 压缩，直接写入响应体
-		encoder := json.NewEncoder(w)
-		encoder.SetEscapeHTML(escapeHTML) // 设置 HTML 转义
-		if err := encoder.Encode(resp); err != nil {
-			// 处理序列化错误
-			http.Error(w, "JSON 编码错误", http.StatusInternalServerError)
-			return
+		payload = compressed
+		w.Header().Set("Content-Encoding", encoding)
+	}
+
+	// 设置响应头
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(payload)
+}
+
+// compress 使用 gzip 或 deflate 压缩数据
+func compress(data []byte, encoding string) ([]byte, error) {
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	switch encoding {
+	case "gzip":
+		writer = gzip.NewWriter(&buf)
+	case "deflate":
+		fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		if err != nil {
+			return nil, err
+		}
+		writer = fw
+	default:
+		return data, nil
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// negotiateEncoding 解析 Accept-Encoding（支持 q 值、* 与 identity），返回 "gzip"、"deflate" 或表示不压缩的空字符串
+// q 值相同时优先 gzip
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		qualities[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range []string{"gzip", "deflate"} {
+		q, found := qualities[encoding]
+		if !found {
+			q = qualities["*"]
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	// 客户端明确更偏好不压缩
+	if q, found := qualities["identity"]; found && q > bestQ {
+		return ""
+	}
+	return best
+}
+
+// ResponseError 可由自定义错误类型实现，用于直接指定错误响应的 HTTP 状态码与 Resp.Code，
+// 实现该接口的错误其 Error() 被视为可以公开的信息
+type ResponseError interface {
+	error
+	ResponseStatus() int // HTTP 状态码
+	ResponseCode() int   // Resp.Code
+}
+
+// errorMapping 错误与响应之间的映射
+type errorMapping struct {
+	target error // 通过 errors.Is 匹配的错误
+	status int   // HTTP 状态码
+	code   int   // Resp.Code
+}
+
+// errorTable 错误码与 HTTP 状态码的映射表，以及按 errors.Is 匹配的错误映射
+var errorTable = struct {
+	mu       sync.RWMutex
+	statuses map[int]int
+	mappings []errorMapping
+}{
+	statuses: map[int]int{
+		CodeInvalidParam:   http.StatusBadRequest,
+		CodeEmptyElement:   http.StatusBadRequest,
+		CodeInvalidCursor:  http.StatusBadRequest,
+		CodeUnauthorized:   http.StatusUnauthorized,
+		CodeForbidden:      http.StatusForbidden,
+		CodeNotFound:       http.StatusNotFound,
+		CodeFileNotFound:   http.StatusNotFound,
+		CodeInternal:       http.StatusInternalServerError,
+		CodeNoMessage:      http.StatusInternalServerError,
+		CodeUnknown:        http.StatusInternalServerError,
+		CodeEmptyCursorKey: http.StatusInternalServerError,
+	},
+}
+
+// debugMode 调试模式下错误响应包含完整的错误信息
+var debugMode atomic.Bool
+
+// SetDebugMode 设置是否为调试模式，默认关闭（生产模式）
+// 生产模式下错误响应只包含可公开的消息，未知错误统一返回“服务器内部错误”；
+// 调试模式下返回完整的错误链信息，便于排查问题
+func SetDebugMode(enabled bool) {
+	debugMode.Store(enabled)
+}
+
+// RegisterErrorCode 注册错误码对应的 HTTP 状态码，用于未指定 Status 的 *Error
+func RegisterErrorCode(code, status int) {
+	errorTable.mu.Lock()
+	defer errorTable.mu.Unlock()
+
+	errorTable.statuses[code] = status
+}
+
+// RegisterErrorMapping 注册通过 errors.Is 匹配的错误映射，例如将 sql.ErrNoRows 映射为 404，
+// 响应消息取错误码在目录中对应的消息
+func RegisterErrorMapping(target error, status, code int) {
+	errorTable.mu.Lock()
+	defer errorTable.mu.Unlock()
+
+	errorTable.mappings = append(errorTable.mappings, errorMapping{target: target, status: status, code: code})
+}
+
+// ErrorResponse 将任意错误转换为 HTTP 状态码与响应体
+// 匹配顺序：ResponseError 接口、*Error、RegisterErrorMapping 注册的错误，都不匹配时视为内部错误；
+// 错误码为 0 或状态码未知的 *Error 不会被公开，带消息模板参数的 *Error 只返回状态码对应的通用消息
+// 参数:
+//   - err: 要转换的错误
+//   - locale: 响应消息的语言，为空时使用全局默认语言
+//
+// 返回值:
+//   - int: HTTP 状态码
+//   - Resp: 响应体
+func ErrorResponse(err error, locale string) (int, Resp) {
+	if locale == "" {
+		locale = Locale()
+	}
+	status, code, message := resolveError(err, locale)
+	if debugMode.Load() && err != nil {
+		message = err.Error()
+	}
+	return status, Resp{Code: code, Status: false, Message: message}
+}
+
+// WriteError 将错误转换为响应并写入
+// r 不为 nil 时根据 Accept-Language 选择语言并通过 WriteResponseWithRequest 写入，否则通过 WriteResponse 写入
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	if r == nil {
+		status, resp := ErrorResponse(err, "")
+		WriteResponse(w, status, resp)
+		return
+	}
+	status, resp := ErrorResponse(err, LocaleFromRequest(r))
+	WriteResponseWithRequest(w, r, status, resp)
+}
+
+// publicMessageCode 获取 HTTP 状态码对应的通用消息的错误码
+func publicMessageCode(status int) int {
+	switch {
+	case status == http.StatusUnauthorized:
+		return CodeUnauthorized
+	case status == http.StatusForbidden:
+		return CodeForbidden
+	case status == http.StatusNotFound:
+		return CodeNotFound
+	case status >= 400 && status < 500:
+		return CodeInvalidParam
+	default:
+		return CodeInternal
+	}
+}
+
+// resolveError 解析错误对应的 HTTP 状态码、错误码与可公开的消息
+func resolveError(err error, locale string) (int, int, string) {
+	var responseErr ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.ResponseStatus(), responseErr.ResponseCode(), responseErr.Error()
+	}
+
+	errorTable.mu.RLock()
+	defer errorTable.mu.RUnlock()
+
+	// 错误码为 0 或未指定状态码且未注册的 *Error（例如 Miss 创建的错误）视为未知错误，
+	// 其消息可能包含内部信息，继续按注册的映射匹配
+	var e *Error
+	if errors.As(err, &e) && e.Code != 0 {
+		status := e.Status
+		if status == 0 {
+			status = errorTable.statuses[e.Code]
+		}
+		if status != 0 {
+			if len(e.args) > 0 {
+				// 消息模板参数可能包含文件路径等调用方传入的内部信息，只返回通用消息
+				return status, e.Code, Translate(locale, publicMessageCode(status))
+			}
+			return status, e.Code, e.Localize(locale)
+		}
+	}
+
+	for _, mapping := range errorTable.mappings {
+		if errors.Is(err, mapping.target) {
+			return mapping.status, mapping.code, Translate(locale, mapping.code)
 		}
 	}
+	return http.StatusInternalServerError, CodeInternal, Translate(locale, CodeInternal)
 }
@@ -0,0 +1,242 @@
+package ji
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// quotaError 实现 ResponseError 的自定义错误
+type quotaError struct{}
+
+func (quotaError) Error() string       { return "超出配额" }
+func (quotaError) ResponseStatus() int { return http.StatusTooManyRequests }
+func (quotaError) ResponseCode() int   { return 42900 }
+
+// unwrapWriter 包装 ResponseWriter 并支持 Unwrap，模拟其他中间件
+type unwrapWriter struct{ http.ResponseWriter }
+
+func (u unwrapWriter) Unwrap() http.ResponseWriter { return u.ResponseWriter }
+
+func TestWriteError(t *testing.T) {
+	RegisterErrorMapping(sql.ErrNoRows, http.StatusNotFound, CodeNotFound)
+	_, fileErr := FileSize("/srv/secret/config.yaml")
+	cases := []struct {
+		err     error
+		status  int
+		code    int
+		message string
+	}{
+		{NewCodeError(CodeFileNotFound, "a.txt").Wrap(errors.New("stat a.txt")), http.StatusNotFound, CodeFileNotFound, "resource not found"},
+		{fileErr, http.StatusNotFound, CodeFileNotFound, "resource not found"},
+		{NewCodeError(CodeUnauthorized), http.StatusUnauthorized, CodeUnauthorized, "not logged in or session expired"},
+		{Miss("db password=hunter2 failed"), http.StatusInternalServerError, CodeInternal, "internal server error"},
+		{NewError(90002, "未注册: /srv/app").Wrap(sql.ErrNoRows), http.StatusNotFound, CodeNotFound, "resource not found"},
+		{NewError(40900, "已存在").WithStatus(http.StatusConflict), http.StatusConflict, 40900, "已存在"},
+		{fmt.Errorf("查询: %w", sql.ErrNoRows), http.StatusNotFound, CodeNotFound, "resource not found"},
+		{fmt.Errorf("包装: %w", quotaError{}), http.StatusTooManyRequests, 42900, "超出配额"},
+		{errors.New("dial tcp 10.0.0.1: refused"), http.StatusInternalServerError, CodeInternal, "internal server error"},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Language", "en-US")
+		w := httptest.NewRecorder()
+		WriteError(w, r, c.err)
+
+		var resp Resp
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("解析响应失败: %v", err)
+		}
+		if w.Code != c.status || resp.Code != c.code || resp.Message != c.message || resp.Status {
+			t.Fatalf("错误 %v 期望响应为 %d/%d/%s，实际为 %d/%+v", c.err, c.status, c.code, c.message, w.Code, resp)
+		}
+	}
+
+	// 测试调试模式返回完整错误信息
+	SetDebugMode(true)
+	defer SetDebugMode(false)
+	if _, resp := ErrorResponse(errors.New("dial tcp"), ""); resp.Message != "dial tcp" {
+		t.Fatalf("期望调试模式下返回完整错误信息，实际为 %v", resp.Message)
+	}
+	if status, resp := ErrorResponse(Miss("db failed"), ""); status != http.StatusInternalServerError || resp.Code != CodeInternal {
+		t.Fatalf("期望调试模式下未知错误的错误码为 %d，实际为 %d/%d", CodeInternal, status, resp.Code)
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                              "",
+		"gzip":                          "gzip",
+		"deflate, gzip;q=0.5":           "deflate",
+		"gzip;q=0, deflate;q=0.1":       "deflate",
+		"br, *;q=0.3":                   "gzip",
+		"*;q=0":                         "",
+		"identity, gzip;q=0.5":          "",
+		"GZIP;q=0.8, identity;q=0.2":    "gzip",
+		"gzip;q=invalid, deflate;q=0.5": "deflate",
+	}
+	for header, expected := range cases {
+		if got := negotiateEncoding(header); got != expected {
+			t.Fatalf("Accept-Encoding %q 期望协商结果为 %q，实际为 %q", header, expected, got)
+		}
+	}
+}
+
+func TestWriteResponseWithRequest(t *testing.T) {
+	large := Resp{Code: 0, Status: true, Data: strings.Repeat("数据", CompressMinSize)}
+
+	// 测试 gzip 压缩
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	WriteResponseWithRequest(w, r, http.StatusOK, large)
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("期望使用 gzip 压缩并设置 Vary，实际响应头为 %v", w.Header())
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("读取 gzip 响应失败: %v", err)
+	}
+	var resp Resp
+	if err := json.NewDecoder(gz).Decode(&resp); err != nil || resp.Data != large.Data {
+		t.Fatalf("解压后的响应错误: %v", err)
+	}
+
+	// 测试 deflate 压缩
+	r.Header.Set("Accept-Encoding", "deflate")
+	w = httptest.NewRecorder()
+	WriteResponseWithRequest(w, r, http.StatusOK, large)
+	if err := json.NewDecoder(flate.NewReader(w.Body)).Decode(&resp); err != nil || w.Header().Get("Content-Encoding") != "deflate" {
+		t.Fatalf("期望使用 deflate 压缩: %v", err)
+	}
+
+	// 测试小响应不压缩
+	r.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	WriteResponseWithRequest(w, r, http.StatusCreated, Resp{Status: true})
+	if w.Code != http.StatusCreated || w.Header().Get("Content-Encoding") != "" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("期望小响应不压缩，实际响应头为 %v", w.Header())
+	}
+}
+
+func TestExecTimeMiddleware(t *testing.T) {
+	handler := ExecTimeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		micro := r.URL.Query().Get("unit") == "us"
+		WriteResponseWithRequest(w, r, http.StatusOK, Resp{Status: true, IncludeExecTime: true, ExecTimeInMicro: micro})
+	}))
+
+	// 测试毫秒
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	var resp Resp
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.ExecTime < 5 {
+		t.Fatalf("期望执行时间不小于 5 毫秒，实际为 %v (%v)", resp.ExecTime, err)
+	}
+	if !strings.HasSuffix(w.Header().Get("X-Response-Time"), "ms") || !strings.HasPrefix(w.Header().Get("Server-Timing"), "app;dur=") {
+		t.Fatalf("期望设置耗时响应头，实际为 %v", w.Header())
+	}
+
+	// 测试微秒
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?unit=us", nil))
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.ExecTime < 5000 {
+		t.Fatalf("期望执行时间不小于 5000 微秒，实际为 %v (%v)", resp.ExecTime, err)
+	}
+
+	// 测试 WriteResponse 从中间件包装的 ResponseWriter 获取开始时间（中间还有其他包装）
+	legacy := ExecTimeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		micro := r.URL.Query().Get("unit") == "us"
+		WriteResponse(unwrapWriter{w}, http.StatusOK, Resp{Status: true, IncludeExecTime: true, ExecTimeInMicro: micro})
+	}))
+	w = httptest.NewRecorder()
+	legacy.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	resp = Resp{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.ExecTime < 5 {
+		t.Fatalf("期望 WriteResponse 的执行时间不小于 5 毫秒，实际为 %v (%v)", resp.ExecTime, err)
+	}
+	w = httptest.NewRecorder()
+	legacy.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?unit=us", nil))
+	resp = Resp{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.ExecTime < 5000 {
+		t.Fatalf("期望 WriteResponse 的执行时间不小于 5000 微秒，实际为 %v (%v)", resp.ExecTime, err)
+	}
+
+	// 测试未经过中间件时 WriteResponse 不写入执行时间
+	w = httptest.NewRecorder()
+	WriteResponse(w, http.StatusOK, Resp{Status: true, IncludeExecTime: true})
+	if strings.Contains(w.Body.String(), "exec_time") {
+		t.Fatalf("期望未经过中间件时不写入执行时间，实际为 %s", w.Body.String())
+	}
+
+	// 测试原始的 ResponseWriter 不支持刷新时，经过中间件后仍能检测到
+	var sseErr error
+	sse := ExecTimeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sseErr = WriteSSE(w, r, func(func(SSEvent) bool) {})
+	}))
+	sse.ServeHTTP(struct{ http.ResponseWriter }{httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !errors.Is(sseErr, http.ErrNotSupported) {
+		t.Fatalf("期望返回 http.ErrNotSupported，实际为 %v", sseErr)
+	}
+	w = httptest.NewRecorder()
+	sse.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if sseErr != nil || !w.Flushed {
+		t.Fatalf("期望支持刷新时正常写入，实际为 %v", sseErr)
+	}
+}
+
+func TestTypedResp(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	// 测试与 Resp 序列化结果一致
+	cases := []struct {
+		typed any
+		resp  Resp
+	}{
+		{OK(user{Name: "张三"}), Resp{Code: 0, Status: true, Data: user{Name: "张三"}}},
+		{Fail[*user](CodeNotFound, "资源不存在"), Resp{Code: CodeNotFound, Message: "资源不存在"}},
+		{OK([]int{1, 2}).ToResp(), Resp{Status: true, Data: []int{1, 2}}},
+		{OK([]int{}), Resp{Status: true, Data: []int{}}},
+		{OK(0), Resp{Status: true, Data: 0}},
+		{OK(false), Resp{Status: true, Data: false}},
+		{OK(""), Resp{Status: true, Data: ""}},
+		{Fail[user](CodeInvalidParam, "bad"), Resp{Code: CodeInvalidParam, Message: "bad"}},
+		{Fail[[]int](CodeInvalidParam, "bad"), Resp{Code: CodeInvalidParam, Message: "bad"}},
+	}
+	for i, c := range cases {
+		typed, _ := json.Marshal(c.typed)
+		resp, _ := json.Marshal(c.resp)
+		if string(typed) != string(resp) {
+			t.Fatalf("用例 %d: 期望 %s，实际为 %s", i, resp, typed)
+		}
+	}
+
+	// 测试分页响应，nil 序列化为空数组
+	body, _ := json.Marshal(Page[user](nil, 0, 1, 20))
+	if string(body) != `{"code":0,"status":true,"data":{"items":[],"total":0,"page":1,"size":20}}` {
+		t.Fatalf("分页响应不符合预期，实际为 %s", body)
+	}
+
+	// 测试写入响应
+	recorder := httptest.NewRecorder()
+	WriteTypedResponse(recorder, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, Page([]user{{Name: "李四"}}, 1, 1, 20))
+	var decoded TypedResp[Paged[user]]
+	if err := json.Unmarshal(recorder.Body.Bytes(), &decoded); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if !decoded.Status || decoded.Data.Total != 1 || decoded.Data.Items[0].Name != "李四" {
+		t.Fatalf("期望解析出分页数据，实际为 %+v", decoded)
+	}
+}
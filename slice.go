@@ -69,18 +69,6 @@ func MustSliceConvert[S any, D any](srcS []S, convert func(src S) D) []D {
 	return res
 }
 
-// MergeErrors 合并多个错误信息为一个错误
-// 将所有错误的字符串信息拼接起来，并返回一个新的错误
-func MergeErrors(_errs ...error) error {
-	errStr := strings.Join(MustSliceConvert(_errs, func(err error) string {
-		return err.Error()
-	}), "\n")
-	if errStr != "" {
-		return Miss(errStr)
-	}
-	return nil
-}
-
 // SliceMeet 检查切片中是否存在满足条件的元素
 // 根据给定的条件函数，如果切片中存在满足条件的元素，返回 true
 // 否则返回 false
@@ -122,7 +110,7 @@ func SplitStringToIntSlice(record string) ([]int, error) {
 		num, err := strconv.Atoi(s)
 		// 如果遇到空字符串，返回错误
 		if s == "" {
-			return nil, fmt.Errorf("输入的字符串在索引 %d 处为空", i)
+			return nil, newBareCodeError(CodeEmptyElement, i)
 		}
 		if err != nil {
 			return nil, err
@@ -159,7 +147,7 @@ func SplitStringToUintSlice(record string) ([]uint, error) {
 	for i, s := range strSlice {
 		// 如果遇到空字符串，返回错误
 		if s == "" {
-			return nil, fmt.Errorf("输入的字符串在索引 %d 处为空", i)
+			return nil, newBareCodeError(CodeEmptyElement, i)
 		}
 		num, err := strconv.ParseUint(s, 10, 32)
 		if err != nil {
@@ -0,0 +1,246 @@
+package ji
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteNDJSON 以 NDJSON（每行一个 JSON 值）格式流式写入数据，每写入一条数据刷新一次
+// 客户端断开（r.Context() 结束）时停止迭代并返回 context 的错误；
+// 响应头写入后无法再修改状态码，编码或写入失败时直接返回错误
+// 参数:
+//   - w: HTTP 响应写入器
+//   - r: HTTP 请求
+//   - items: 要写入的数据，阻塞的数据源应自行响应 r.Context()，例如使用 ChanSeq
+func WriteNDJSON[T any](w http.ResponseWriter, r *http.Request, items iter.Seq[T]) error {
+	w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	encoder := json.NewEncoder(w)
+	for item := range items {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+		flush(rc)
+	}
+	return r.Context().Err()
+}
+
+// WriteJSONArray 以 JSON 数组格式流式写入数据，数据逐条编码写入，不会一次性加载到内存
+// 客户端断开时停止迭代并返回 context 的错误，此时写入的数组不完整
+func WriteJSONArray[T any](w http.ResponseWriter, r *http.Request, items iter.Seq[T]) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if _, err := w.Write([]byte("[")); err != nil {
+		return err
+	}
+	first := true
+	for item := range items {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if !first {
+			data = append([]byte(","), data...)
+		}
+		first = false
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		flush(rc)
+	}
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte("]")); err != nil {
+		return err
+	}
+	flush(rc)
+	return nil
+}
+
+// ChanSeq 将 channel 转换为 iter.Seq，channel 关闭或 ctx 结束时停止
+func ChanSeq[T any](ctx context.Context, ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok := <-ch:
+				if !ok || !yield(item) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// SSEvent Server-Sent Events 事件
+type SSEvent struct {
+	ID    string        // 事件 ID，客户端重连时通过 Last-Event-ID 请求头带回，为空时不写入
+	Event string        // 事件类型，为空时客户端按 message 事件处理
+	Data  any           // 事件数据，string 与 []byte 原样写入（多行会拆分为多个 data 字段），其他类型编码为 JSON
+	Retry time.Duration // 客户端重连间隔，为 0 时不写入
+}
+
+// SSEOption Server-Sent Events 写入选项
+type SSEOption func(*sseConfig)
+
+// sseConfig Server-Sent Events 写入配置
+type sseConfig struct {
+	retry     time.Duration
+	heartbeat time.Duration
+}
+
+// WithSSERetry 设置连接建立时发送的客户端重连间隔
+func WithSSERetry(retry time.Duration) SSEOption {
+	return func(c *sseConfig) {
+		c.retry = retry
+	}
+}
+
+// WithSSEHeartbeat 设置心跳间隔，没有事件时定期发送注释行，防止代理因空闲断开连接
+func WithSSEHeartbeat(interval time.Duration) SSEOption {
+	return func(c *sseConfig) {
+		c.heartbeat = interval
+	}
+}
+
+// LastEventID 获取客户端重连时带回的最后一个事件 ID
+func LastEventID(r *http.Request) string {
+	return r.Header.Get("Last-Event-ID")
+}
+
+// WriteSSE 以 Server-Sent Events 格式流式写入事件，每个事件写入后立即刷新
+// 事件在单独的 goroutine 中迭代，因此等待事件期间也能发送心跳并感知客户端断开；
+// 客户端断开时停止迭代并返回 context 的错误，事件全部写入后返回 nil
+// 参数:
+//   - w: HTTP 响应写入器，需要支持 http.Flusher
+//   - r: HTTP 请求
+//   - events: 要写入的事件
+//   - opts: 可选配置，例如 WithSSERetry、WithSSEHeartbeat
+func WriteSSE(w http.ResponseWriter, r *http.Request, events iter.Seq[SSEvent], opts ...SSEOption) error {
+	var config sseConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	rc := http.NewResponseController(w)
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream; charset=utf-8")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if config.retry > 0 {
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", config.retry.Milliseconds()); err != nil {
+			return err
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return fmt.Errorf("响应不支持流式写入: %w", err)
+	}
+
+	// 在单独的 goroutine 中迭代事件，done 关闭后停止迭代
+	ctx := r.Context()
+	queue := make(chan SSEvent)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(queue)
+		for event := range events {
+			select {
+			case queue <- event:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var heartbeat <-chan time.Time
+	if config.heartbeat > 0 {
+		ticker := time.NewTicker(config.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-heartbeat:
+			if _, err := w.Write([]byte(": heartbeat\n\n")); err != nil {
+				return err
+			}
+		case event, ok := <-queue:
+			if !ok {
+				// 事件源可能因 ctx 结束而停止（例如 ChanSeq）
+				return ctx.Err()
+			}
+			data, err := encodeSSEvent(event)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+		flush(rc)
+	}
+}
+
+// encodeSSEvent 将事件编码为 text/event-stream 格式
+func encodeSSEvent(event SSEvent) ([]byte, error) {
+	var data string
+	switch v := event.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data = string(encoded)
+	}
+
+	// ID 与事件类型中的换行会破坏事件格式，直接移除
+	newline := strings.NewReplacer("\r", "", "\n", "")
+	var builder strings.Builder
+	if event.ID != "" {
+		builder.WriteString("id: " + newline.Replace(event.ID) + "\n")
+	}
+	if event.Event != "" {
+		builder.WriteString("event: " + newline.Replace(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		builder.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	data = strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\r", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		builder.WriteString("data: " + line + "\n")
+	}
+	builder.WriteString("\n")
+	return []byte(builder.String()), nil
+}
+
+// flush 刷新响应，ResponseWriter 不支持刷新时忽略
+func flush(rc *http.ResponseController) {
+	_ = rc.Flush()
+}
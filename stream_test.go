@@ -0,0 +1,132 @@
+package ji
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteNDJSON(t *testing.T) {
+	type item struct {
+		ID int `json:"id"`
+	}
+	items := []item{{1}, {2}, {3}}
+
+	// 测试每行一个 JSON 值
+	recorder := httptest.NewRecorder()
+	if err := WriteNDJSON(recorder, httptest.NewRequest(http.MethodGet, "/", nil), slices.Values(items)); err != nil {
+		t.Fatalf("写入 NDJSON 失败: %v", err)
+	}
+	if body := recorder.Body.String(); body != "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n" {
+		t.Fatalf("NDJSON 内容不符合预期，实际为 %q", body)
+	}
+	if !recorder.Flushed || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "application/x-ndjson") {
+		t.Fatalf("期望刷新响应并设置 NDJSON 类型，实际为 %v %q", recorder.Flushed, recorder.Header().Get("Content-Type"))
+	}
+
+	// 测试 JSON 数组，包括空数组
+	recorder = httptest.NewRecorder()
+	if err := WriteJSONArray(recorder, httptest.NewRequest(http.MethodGet, "/", nil), slices.Values(items)); err != nil {
+		t.Fatalf("写入 JSON 数组失败: %v", err)
+	}
+	var decoded []item
+	if err := json.Unmarshal(recorder.Body.Bytes(), &decoded); err != nil || !slices.Equal(decoded, items) {
+		t.Fatalf("期望解析出 %v，实际为 %v, %v", items, decoded, err)
+	}
+	recorder = httptest.NewRecorder()
+	WriteJSONArray(recorder, httptest.NewRequest(http.MethodGet, "/", nil), slices.Values([]item(nil)))
+	if recorder.Body.String() != "[]" {
+		t.Fatalf("期望空数组，实际为 %q", recorder.Body.String())
+	}
+
+	// 测试客户端断开时停止迭代
+	ctx, cancel := context.WithCancel(context.Background())
+	request := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	var count int
+	seq := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			count++
+			if i == 2 {
+				cancel()
+			}
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	if err := WriteNDJSON(httptest.NewRecorder(), request, seq); !errors.Is(err, context.Canceled) || count != 3 {
+		t.Fatalf("期望取消后停止迭代，实际为 %v，迭代 %d 次", err, count)
+	}
+}
+
+func TestChanSeq(t *testing.T) {
+	// 测试 channel 关闭时停止
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	close(ch)
+	if got := slices.Collect(ChanSeq(context.Background(), ch)); !slices.Equal(got, []int{1, 2}) {
+		t.Fatalf("期望 [1 2]，实际为 %v", got)
+	}
+
+	// 测试 ctx 结束时停止
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := slices.Collect(ChanSeq(ctx, make(chan int))); len(got) != 0 {
+		t.Fatalf("期望 ctx 结束后没有数据，实际为 %v", got)
+	}
+}
+
+func TestWriteSSE(t *testing.T) {
+	// 测试事件编码
+	data, _ := encodeSSEvent(SSEvent{ID: "1\n", Event: "progress", Data: "第一行\n第二行", Retry: time.Second})
+	if string(data) != "id: 1\nevent: progress\nretry: 1000\ndata: 第一行\ndata: 第二行\n\n" {
+		t.Fatalf("事件编码不符合预期，实际为 %q", data)
+	}
+	data, _ = encodeSSEvent(SSEvent{Data: map[string]int{"percent": 50}})
+	if string(data) != "data: {\"percent\":50}\n\n" {
+		t.Fatalf("JSON 事件编码不符合预期，实际为 %q", data)
+	}
+
+	// 测试事件全部写入后返回
+	events := []SSEvent{{ID: "1", Data: "a"}, {ID: "2", Data: "b"}}
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := WriteSSE(recorder, request, slices.Values(events), WithSSERetry(3*time.Second)); err != nil {
+		t.Fatalf("写入 SSE 失败: %v", err)
+	}
+	if body := recorder.Body.String(); body != "retry: 3000\n\nid: 1\ndata: a\n\nid: 2\ndata: b\n\n" {
+		t.Fatalf("SSE 内容不符合预期，实际为 %q", body)
+	}
+	if recorder.Header().Get("Content-Type") != "text/event-stream; charset=utf-8" {
+		t.Fatalf("期望 text/event-stream，实际为 %q", recorder.Header().Get("Content-Type"))
+	}
+
+	// 测试等待事件期间发送心跳，客户端断开时返回
+	ctx, cancel := context.WithCancel(context.Background())
+	request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	request.Header.Set("Last-Event-ID", "2")
+	ch := make(chan SSEvent)
+	go func() {
+		ch <- SSEvent{ID: "3", Data: "c"}
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	recorder = httptest.NewRecorder()
+	err := WriteSSE(recorder, request, ChanSeq(ctx, ch), WithSSEHeartbeat(10*time.Millisecond))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("期望返回 context.Canceled，实际为 %v", err)
+	}
+	if body := recorder.Body.String(); !strings.Contains(body, "id: 3\ndata: c\n\n") || !strings.Contains(body, ": heartbeat\n\n") {
+		t.Fatalf("期望包含事件与心跳，实际为 %q", body)
+	}
+	if LastEventID(request) != "2" {
+		t.Fatalf("期望 Last-Event-ID 为 2，实际为 %q", LastEventID(request))
+	}
+}
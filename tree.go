@@ -2,7 +2,7 @@ package ji
 
 import (
 	"container/list"
-	"context"
+	"slices"
 	"sync"
 )
 
@@ -18,39 +18,72 @@ type TreeNode struct {
 
 // Tree 结构，封装高效的树操作
 type Tree struct {
-	nodes map[uint]*TreeNode // 快速查找节点
-	mu    sync.RWMutex       // 读写锁，确保并发安全
+	nodes    map[uint]*TreeNode // 快速查找节点
+	mu       sync.RWMutex       // 读写锁，确保并发安全
+	config   treeConfig         // 构建配置
+	cache    treeCache          // 子树聚合结果缓存
+	assigned map[uint]struct{}  // RootByMissingParent 规则下 ParentID 由树自动设置的根节点，新增节点时不会被收养
 }
 
 // NewTree 创建一个新的树结构
-func NewTree(data []TreeNode) (*Tree, error) {
-	tree := &Tree{nodes: make(map[uint]*TreeNode)}
-
-	// 预先构建节点映射
+// 参数:
+//   - data: 扁平的节点列表，通过 ParentID 关联父子关系
+//   - opts: 可选配置，例如 WithOrphansAsRoots
+//
+// 返回值:
+//   - *Tree: 构建好的树
+//   - error: 存在重复 ID、自引用、环或孤儿节点时返回 *TreeError，列出所有问题节点
+func NewTree(data []TreeNode, opts ...TreeOption) (*Tree, error) {
+	tree := &Tree{nodes: make(map[uint]*TreeNode, len(data)), config: newTreeConfig(opts)}
+
+	// 预先构建节点映射，按输入顺序记录有效节点
+	var issues []TreeIssue
+	ordered := make([]*TreeNode, 0, len(data))
 	for i := range data {
+		if _, exists := tree.nodes[data[i].ID]; exists {
+			issues = append(issues, TreeIssue{ID: data[i].ID, Kind: TreeIssueDuplicateID})
+			continue
+		}
 		tree.nodes[data[i].ID] = &data[i]
+		ordered = append(ordered, &data[i])
+	}
+
+	// 校验父子关系
+	issues = append(issues, tree.validate(ordered)...)
+	if len(issues) > 0 {
+		return nil, &TreeError{Issues: issues}
 	}
 
 	// 构建树结构
-	for _, node := range tree.nodes {
+	for _, node := range ordered {
+		if tree.isRoot(node) {
+			continue
+		}
 		if parent, found := tree.nodes[node.ParentID]; found {
 			parent.Children = append(parent.Children, node)
 		}
 	}
+	tree.sortAll()
 	return tree, nil
 }
 
-// GetRootNodes 获取所有的根节点（ParentID = 0）
+// GetRootNodes 获取所有的根节点（默认为 ParentID = 0，见 WithRootRule），按排序规则排列
 func (tree *Tree) GetRootNodes() []*TreeNode {
 	tree.mu.RLock()
 	defer tree.mu.RUnlock()
 
+	return tree.rootNodes()
+}
+
+// rootNodes 获取排序后的根节点（调用方需持有读锁）
+func (tree *Tree) rootNodes() []*TreeNode {
 	var rootNodes []*TreeNode
 	for _, node := range tree.nodes {
-		if node.ParentID == 0 {
+		if tree.isRoot(node) {
 			rootNodes = append(rootNodes, node)
 		}
 	}
+	slices.SortStableFunc(rootNodes, tree.config.compareFunc())
 	return rootNodes
 }
 
@@ -99,7 +132,7 @@ func (tree *Tree) TreeLevel(nodeID uint) int {
 		return -1 // 如果节点未找到，返回 -1
 	}
 	// 从当前节点开始，沿着父节点向上遍历，直到根节点
-	for currentNode.ParentID != 0 {
+	for !tree.isRoot(currentNode) {
 		level++
 		currentNode = tree.nodes[currentNode.ParentID]
 	}
@@ -115,66 +148,34 @@ func (tree *Tree) IsParent(nodeID, parentID uint) bool {
 	if !found {
 		return false
 	}
-	return node.ParentID == parentID
+	return nodeID != parentID && node.ParentID == parentID
 }
 
-// IsInSubTreeConcurrent 并发查找目标 ID 是否在子树中
-func (tree *Tree) IsInSubTreeConcurrent(rootID, targetID uint) bool {
+// IsInSubTree 判断 targetID 是否位于 rootID 的子树中（包含 rootID 自身）
+// 沿 targetID 的 ParentID 向上查找，复杂度为 O(深度)，全程持有读锁
+func (tree *Tree) IsInSubTree(rootID, targetID uint) bool {
 	tree.mu.RLock()
-	rootNode, found := tree.nodes[rootID]
-	tree.mu.RUnlock()
-	if !found {
+	defer tree.mu.RUnlock()
+
+	if _, found := tree.nodes[rootID]; !found {
 		return false
 	}
-
-	// 创建取消上下文
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// 结果通道
-	resultChan := make(chan bool, 1)
-	var wg sync.WaitGroup
-
-	wg.Add(1)
-	// 并发查找子树
-	go func() {
-		defer wg.Done()
-		tree.searchSubtree(ctx, rootNode, targetID, resultChan)
-	}()
-
-	// 等待查找完成
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	return <-resultChan
-}
-
-// searchSubtree 递归并发搜索子树
-func (tree *Tree) searchSubtree(ctx context.Context, node *TreeNode, targetID uint, resultChan chan<- bool) {
-	select {
-	case <-ctx.Done():
-		return
-	default:
-		// 如果找到了目标节点
-		if node.ID == targetID {
-			select {
-			case resultChan <- true:
-			default:
-			}
-			return
+	currentNode, found := tree.nodes[targetID]
+	for found {
+		if currentNode.ID == rootID {
+			return true
 		}
-
-		var wg sync.WaitGroup
-		// 并发搜索子节点
-		for _, child := range node.Children {
-			wg.Add(1)
-			go func(childNode *TreeNode) {
-				defer wg.Done()
-				tree.searchSubtree(ctx, childNode, targetID, resultChan)
-			}(child)
+		if tree.isRoot(currentNode) {
+			return false
 		}
-		wg.Wait()
+		currentNode, found = tree.nodes[currentNode.ParentID]
 	}
+	return false
+}
+
+// IsInSubTreeConcurrent 判断目标 ID 是否在子树中
+//
+// Deprecated: 原实现为每个节点启动一个 goroutine 且遍历时未加锁，现直接调用 IsInSubTree
+func (tree *Tree) IsInSubTreeConcurrent(rootID, targetID uint) bool {
+	return tree.IsInSubTree(rootID, targetID)
 }
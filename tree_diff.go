@@ -0,0 +1,131 @@
+package ji
+
+// TreeMove 节点的父节点变更
+type TreeMove struct {
+	ID   uint `json:"id"`   // 节点 ID
+	From uint `json:"from"` // 原父节点 ID
+	To   uint `json:"to"`   // 新父节点 ID
+}
+
+// TreeRename 节点的名称变更
+type TreeRename struct {
+	ID   uint   `json:"id"`   // 节点 ID
+	From string `json:"from"` // 原名称
+	To   string `json:"to"`   // 新名称
+}
+
+// TreeReorder 节点的排序变更
+type TreeReorder struct {
+	ID   uint `json:"id"`   // 节点 ID
+	From int  `json:"from"` // 原 Sorted 值
+	To   int  `json:"to"`   // 新 Sorted 值
+}
+
+// TreeDiff 两个版本的树之间的差异，可用于审计日志与缓存失效
+type TreeDiff struct {
+	Added     []TreeNode    `json:"added,omitempty"`     // 新增的节点（父节点在前，Children 为空）
+	Removed   []uint        `json:"removed,omitempty"`   // 删除的节点 ID
+	Moved     []TreeMove    `json:"moved,omitempty"`     // 父节点变更的节点
+	Renamed   []TreeRename  `json:"renamed,omitempty"`   // 名称变更的节点
+	Reordered []TreeReorder `json:"reordered,omitempty"` // 排序变更的节点
+}
+
+// IsEmpty 判断是否没有任何差异
+func (d TreeDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Moved) == 0 &&
+		len(d.Renamed) == 0 && len(d.Reordered) == 0
+}
+
+// DiffTree 比较两棵树，返回从 oldTree 变为 newTree 所需的差异
+// 结果中的节点均按先序遍历顺序排列，便于按顺序应用；比较前会复制 oldTree，任意时刻只持有一棵树的读锁
+func DiffTree(oldTree, newTree *Tree) TreeDiff {
+	var diff TreeDiff
+	if oldTree == newTree {
+		return diff
+	}
+
+	// 先复制旧树，避免同时持有两棵树的读锁：并发调用 DiffTree(a, b) 与 DiffTree(b, a)
+	// 且两棵树都有写操作等待时会互相阻塞
+	oldTree = oldTree.Clone()
+	newTree.mu.RLock()
+	defer newTree.mu.RUnlock()
+
+	var walkNew func(node *TreeNode)
+	walkNew = func(node *TreeNode) {
+		oldNode, found := oldTree.nodes[node.ID]
+		if !found {
+			added := *node
+			added.Children = nil
+			diff.Added = append(diff.Added, added)
+		} else {
+			if oldNode.ParentID != node.ParentID {
+				diff.Moved = append(diff.Moved, TreeMove{ID: node.ID, From: oldNode.ParentID, To: node.ParentID})
+			}
+			if oldNode.Name != node.Name {
+				diff.Renamed = append(diff.Renamed, TreeRename{ID: node.ID, From: oldNode.Name, To: node.Name})
+			}
+			if oldNode.Sorted != node.Sorted {
+				diff.Reordered = append(diff.Reordered, TreeReorder{ID: node.ID, From: oldNode.Sorted, To: node.Sorted})
+			}
+		}
+		for _, child := range node.Children {
+			walkNew(child)
+		}
+	}
+	for _, root := range newTree.rootNodes() {
+		walkNew(root)
+	}
+
+	var walkOld func(node *TreeNode)
+	walkOld = func(node *TreeNode) {
+		if _, found := newTree.nodes[node.ID]; !found {
+			diff.Removed = append(diff.Removed, node.ID)
+		}
+		for _, child := range node.Children {
+			walkOld(child)
+		}
+	}
+	for _, root := range oldTree.rootNodes() {
+		walkOld(root)
+	}
+	return diff
+}
+
+// ApplyDiff 将差异应用到树上，依次执行新增、移动、重命名、排序与删除
+// 删除的节点连同其剩余子树一起删除；遇到错误时立即返回，已应用的变更不会回滚
+func (tree *Tree) ApplyDiff(diff TreeDiff) error {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	for _, node := range diff.Added {
+		if err := tree.addNode(node); err != nil {
+			return err
+		}
+	}
+	// 移动按新树的先序顺序执行，保证目标父节点的祖先链已是最终状态，不会误判为环
+	for _, move := range diff.Moved {
+		if err := tree.moveNode(move.ID, move.To); err != nil {
+			return err
+		}
+	}
+	for _, rename := range diff.Renamed {
+		if err := tree.renameNode(rename.ID, rename.To); err != nil {
+			return err
+		}
+	}
+	for _, reorder := range diff.Reordered {
+		if err := tree.setSorted(reorder.ID, reorder.To); err != nil {
+			return err
+		}
+	}
+	for _, nodeID := range diff.Removed {
+		// 祖先节点已被删除时，该节点会随子树一起删除
+		if _, found := tree.nodes[nodeID]; !found {
+			continue
+		}
+		if err := tree.deleteNode(nodeID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package ji
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NestedSetRow 嵌套集合（左右值）模型的一行
+type NestedSetRow struct {
+	ID     uint   `json:"id"`     // 节点 ID
+	Name   string `json:"name"`   // 节点名称
+	Sorted int    `json:"sorted"` // 排序
+	Lft    int    `json:"lft"`    // 左值
+	Rgt    int    `json:"rgt"`    // 右值
+	Depth  int    `json:"depth"`  // 深度，根节点为 0
+}
+
+// PathRow 物化路径模型的一行
+type PathRow struct {
+	ID     uint   `json:"id"`     // 节点 ID
+	Name   string `json:"name"`   // 节点名称
+	Sorted int    `json:"sorted"` // 排序
+	Path   string `json:"path"`   // 从根节点到当前节点的 ID 路径，例如 "/1/4/9/"
+}
+
+// ClosureRow 闭包表模型的一行
+type ClosureRow struct {
+	Ancestor   uint `json:"ancestor"`   // 祖先节点 ID
+	Descendant uint `json:"descendant"` // 子孙节点 ID
+	Depth      int  `json:"depth"`      // 两者之间的距离，节点与自身的距离为 0
+}
+
+// NestedSet 导出为嵌套集合（左右值）行，按先序遍历顺序排列，多个根节点的左右值连续编号
+func (tree *Tree) NestedSet() []NestedSetRow {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	rows := make([]NestedSetRow, 0, len(tree.nodes))
+	counter := 0
+	var walk func(node *TreeNode, depth int)
+	walk = func(node *TreeNode, depth int) {
+		counter++
+		index := len(rows)
+		rows = append(rows, NestedSetRow{ID: node.ID, Name: node.Name, Sorted: node.Sorted, Lft: counter, Depth: depth})
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+		counter++
+		rows[index].Rgt = counter
+	}
+	for _, root := range tree.rootNodes() {
+		walk(root, 0)
+	}
+	return rows
+}
+
+// MaterializedPaths 导出为物化路径行，按先序遍历顺序排列
+func (tree *Tree) MaterializedPaths() []PathRow {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	rows := make([]PathRow, 0, len(tree.nodes))
+	var walk func(node *TreeNode, prefix string)
+	walk = func(node *TreeNode, prefix string) {
+		path := prefix + strconv.FormatUint(uint64(node.ID), 10) + "/"
+		rows = append(rows, PathRow{ID: node.ID, Name: node.Name, Sorted: node.Sorted, Path: path})
+		for _, child := range node.Children {
+			walk(child, path)
+		}
+	}
+	for _, root := range tree.rootNodes() {
+		walk(root, "/")
+	}
+	return rows
+}
+
+// ClosureTable 导出为闭包表行，包含每个节点与自身（Depth 为 0）以及与所有祖先的关系
+func (tree *Tree) ClosureTable() []ClosureRow {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	var rows []ClosureRow
+	var ancestors []uint
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		ancestors = append(ancestors, node.ID)
+		for i, ancestor := range ancestors {
+			rows = append(rows, ClosureRow{Ancestor: ancestor, Descendant: node.ID, Depth: len(ancestors) - 1 - i})
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+		ancestors = ancestors[:len(ancestors)-1]
+	}
+	for _, root := range tree.rootNodes() {
+		walk(root)
+	}
+	return rows
+}
+
+// NewTreeFromNestedSet 从嵌套集合（左右值）行重建树，左右值重复或区间交叉时返回错误
+func NewTreeFromNestedSet(rows []NestedSetRow, opts ...TreeOption) (*Tree, error) {
+	// 所有节点的左右值必须互不相同
+	values := make(map[int]uint, len(rows)*2)
+	for _, row := range rows {
+		for _, value := range []int{row.Lft, row.Rgt} {
+			if id, exists := values[value]; exists {
+				return nil, fmt.Errorf("节点 %d 与节点 %d 的左右值重复: %d", row.ID, id, value)
+			}
+			values[value] = row.ID
+		}
+	}
+
+	sorted := slices.Clone(rows)
+	slices.SortFunc(sorted, func(a, b NestedSetRow) int {
+		return cmp.Compare(a.Lft, b.Lft)
+	})
+
+	data := make([]TreeNode, 0, len(sorted))
+	var roots []int
+	var stack []NestedSetRow
+	for _, row := range sorted {
+		if row.Lft >= row.Rgt {
+			return nil, fmt.Errorf("节点 %d 的左右值无效: lft=%d, rgt=%d", row.ID, row.Lft, row.Rgt)
+		}
+		// 弹出已经结束的祖先区间
+		for len(stack) > 0 && stack[len(stack)-1].Rgt < row.Lft {
+			stack = stack[:len(stack)-1]
+		}
+		var parentID uint
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1]
+			if row.Rgt > parent.Rgt {
+				return nil, fmt.Errorf("节点 %d 的区间与节点 %d 交叉", row.ID, parent.ID)
+			}
+			parentID = parent.ID
+		} else {
+			roots = append(roots, len(data))
+		}
+		stack = append(stack, row)
+		data = append(data, TreeNode{ID: row.ID, ParentID: parentID, Name: row.Name, Sorted: row.Sorted})
+	}
+	return newTreeWithRoots(data, roots, opts)
+}
+
+// NewTreeFromMaterializedPaths 从物化路径行重建树，路径的最后一段必须是节点自身的 ID，
+// 且去掉最后一段后必须与父节点的路径一致
+func NewTreeFromMaterializedPaths(rows []PathRow, opts ...TreeOption) (*Tree, error) {
+	paths := make([][]uint, len(rows))
+	byID := make(map[uint][]uint, len(rows))
+	for i, row := range rows {
+		ids, err := SplitStringToUintSlice(strings.ReplaceAll(strings.Trim(row.Path, "/"), "/", ","))
+		if err != nil {
+			return nil, fmt.Errorf("节点 %d 的路径 %q 无效: %w", row.ID, row.Path, err)
+		}
+		if ids[len(ids)-1] != row.ID {
+			return nil, fmt.Errorf("节点 %d 的路径 %q 未以自身 ID 结尾", row.ID, row.Path)
+		}
+		paths[i] = ids
+		if _, exists := byID[row.ID]; !exists {
+			byID[row.ID] = ids
+		}
+	}
+
+	data := make([]TreeNode, 0, len(rows))
+	var roots []int
+	for i, row := range rows {
+		ids := paths[i]
+		var parentID uint
+		if len(ids) > 1 {
+			parentID = ids[len(ids)-2]
+			// 父节点不存在时交由 NewTree 按孤儿节点处理
+			if parentPath, found := byID[parentID]; found && !slices.Equal(parentPath, ids[:len(ids)-1]) {
+				return nil, fmt.Errorf("节点 %d 的路径 %q 与父节点 %d 的路径不一致", row.ID, row.Path, parentID)
+			}
+		} else {
+			roots = append(roots, len(data))
+		}
+		data = append(data, TreeNode{ID: row.ID, ParentID: parentID, Name: row.Name, Sorted: row.Sorted})
+	}
+	return newTreeWithRoots(data, roots, opts)
+}
+
+// NewTreeFromClosureTable 从闭包表行重建树
+// 参数:
+//   - nodes: 节点信息，ParentID 会根据闭包表中 Depth 为 1 的行重新设置，没有此类行的节点按根节点规则作为根节点
+//   - rows: 闭包表行
+func NewTreeFromClosureTable(nodes []TreeNode, rows []ClosureRow, opts ...TreeOption) (*Tree, error) {
+	parents := make(map[uint]uint, len(nodes))
+	for _, row := range rows {
+		if row.Depth != 1 {
+			continue
+		}
+		if parentID, exists := parents[row.Descendant]; exists && parentID != row.Ancestor {
+			return nil, fmt.Errorf("节点 %d 存在多个直接父节点: %d, %d", row.Descendant, parentID, row.Ancestor)
+		}
+		parents[row.Descendant] = row.Ancestor
+	}
+
+	data := make([]TreeNode, len(nodes))
+	var roots []int
+	for i, node := range nodes {
+		parentID, found := parents[node.ID]
+		if !found {
+			roots = append(roots, i)
+		}
+		node.ParentID = parentID
+		node.Children = nil
+		data[i] = node
+	}
+	return newTreeWithRoots(data, roots, opts)
+}
@@ -0,0 +1,60 @@
+package ji
+
+import "strings"
+
+// Filter 按条件过滤树，返回一棵新树，包含所有匹配的节点及其祖先节点，
+// 新树中的节点与 Children 均为副本，不与原树共享
+func (tree *Tree) Filter(match func(node *TreeNode) bool) *Tree {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	// 标记匹配节点及其祖先
+	keep := make(map[uint]struct{})
+	for _, node := range tree.nodes {
+		if !match(node) {
+			continue
+		}
+		for current, found := node, true; found; current, found = tree.nodes[current.ParentID] {
+			if _, marked := keep[current.ID]; marked {
+				break
+			}
+			keep[current.ID] = struct{}{}
+			if tree.isRoot(current) {
+				break
+			}
+		}
+	}
+
+	result := &Tree{nodes: make(map[uint]*TreeNode, len(keep)), config: tree.config}
+
+	// 先序复制保留的节点，保持原有的兄弟顺序
+	var clone func(node *TreeNode) *TreeNode
+	clone = func(node *TreeNode) *TreeNode {
+		copied := *node
+		copied.Children = nil
+		for _, child := range node.Children {
+			if _, ok := keep[child.ID]; ok {
+				copied.Children = append(copied.Children, clone(child))
+			}
+		}
+		result.nodes[copied.ID] = &copied
+		if tree.isAssigned(copied.ID) {
+			result.markAssigned(copied.ID)
+		}
+		return &copied
+	}
+	for _, root := range tree.rootNodes() {
+		if _, ok := keep[root.ID]; ok {
+			clone(root)
+		}
+	}
+	return result
+}
+
+// Search 按名称关键字搜索（不区分大小写），返回包含匹配节点及其祖先节点的新树
+func (tree *Tree) Search(keyword string) *Tree {
+	keyword = strings.ToLower(keyword)
+	return tree.Filter(func(node *TreeNode) bool {
+		return strings.Contains(strings.ToLower(node.Name), keyword)
+	})
+}
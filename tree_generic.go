@@ -0,0 +1,319 @@
+package ji
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// GenericNode 泛型树节点，ID 可以是任意可比较类型，Value 为任意负载
+type GenericNode[K comparable, V any] struct {
+	ID       K                    `json:"id"`        // 当前节点 ID
+	ParentID K                    `json:"parent_id"` // 父节点 ID，零值表示根节点
+	Value    V                    `json:"value"`     // 节点负载
+	Children []*GenericNode[K, V] `json:"children"`  // 子节点列表
+}
+
+// GenericTree 泛型树结构，与 Tree 并列的只读类型，只提供基础查询方法
+// Tree 并非基于 GenericTree 实现，路径、排序、选中、遍历、导出等功能以及 AddNode、MoveNode 等修改方法
+// 只在 Tree 上提供；两者可通过 Tree.Generic 与 NewTreeFromGeneric 互相转换
+type GenericTree[K comparable, V any] struct {
+	nodes map[K]*GenericNode[K, V] // 快速查找节点
+	order map[K]int                // 节点的输入顺序，用于稳定排列根节点
+	mu    sync.RWMutex             // 读写锁，确保并发安全
+}
+
+// GenericTreeIssue 描述泛型树中单个节点的校验问题
+type GenericTreeIssue[K comparable] struct {
+	ID   K             `json:"id"`   // 出现问题的节点 ID
+	Kind TreeIssueKind `json:"kind"` // 问题类型
+}
+
+// GenericTreeError 泛型树结构校验错误
+type GenericTreeError[K comparable] struct {
+	Issues []GenericTreeIssue[K] `json:"issues"`
+}
+
+// Error 实现 error 接口
+func (e *GenericTreeError[K]) Error() string {
+	var builder strings.Builder
+	builder.WriteString("树结构校验失败: ")
+	for i, issue := range e.Issues {
+		if i > 0 {
+			builder.WriteString("; ")
+		}
+		builder.WriteString(fmt.Sprintf("节点 %v %s", issue.ID, issue.Kind))
+	}
+	return builder.String()
+}
+
+// NewGenericTree 创建一个新的泛型树结构，校验规则与 NewTree 相同（零值 ID 视为保留 ID）
+// 泛型树始终以 ParentID 为零值的节点作为根节点，子节点与根节点均按输入顺序排列；
+// opts 仅支持 WithOrphansAsRoots，传入 WithTreeCompare 或 WithRootRule 时返回错误
+func NewGenericTree[K comparable, V any](data []GenericNode[K, V], opts ...TreeOption) (*GenericTree[K, V], error) {
+	config := newTreeConfig(opts)
+	if config.compare != nil || config.rootRule != (TreeRootRule{}) {
+		return nil, errors.New("泛型树不支持 WithTreeCompare 与 WithRootRule 选项")
+	}
+	tree := &GenericTree[K, V]{
+		nodes: make(map[K]*GenericNode[K, V], len(data)),
+		order: make(map[K]int, len(data)),
+	}
+
+	var root K
+	var issues []GenericTreeIssue[K]
+	ordered := make([]*GenericNode[K, V], 0, len(data))
+	for i := range data {
+		// 零值 ID 表示“无父节点”，与 NewTree 中 ID 为 0 的节点相同，不允许作为节点 ID
+		if data[i].ID == root {
+			issues = append(issues, GenericTreeIssue[K]{ID: data[i].ID, Kind: TreeIssueReservedID})
+			continue
+		}
+		if _, exists := tree.nodes[data[i].ID]; exists {
+			issues = append(issues, GenericTreeIssue[K]{ID: data[i].ID, Kind: TreeIssueDuplicateID})
+			continue
+		}
+		tree.nodes[data[i].ID] = &data[i]
+		tree.order[data[i].ID] = len(ordered)
+		ordered = append(ordered, &data[i])
+	}
+
+	// 检查自引用与孤儿节点
+	ids := make([]K, len(ordered))
+	for i, node := range ordered {
+		ids[i] = node.ID
+		if node.ParentID == root {
+			continue
+		}
+		if node.ParentID == node.ID {
+			issues = append(issues, GenericTreeIssue[K]{ID: node.ID, Kind: TreeIssueSelfParent})
+			continue
+		}
+		if _, found := tree.nodes[node.ParentID]; !found {
+			if config.orphansAsRoots {
+				node.ParentID = root
+				continue
+			}
+			issues = append(issues, GenericTreeIssue[K]{ID: node.ID, Kind: TreeIssueOrphan})
+		}
+	}
+
+	// 查找环
+	cycles := findCycles(ids, func(id K) (K, bool) {
+		node := tree.nodes[id]
+		if node.ParentID == root || node.ParentID == node.ID {
+			return root, false
+		}
+		_, found := tree.nodes[node.ParentID]
+		return node.ParentID, found
+	})
+	for _, cycle := range cycles {
+		for _, id := range cycle {
+			issues = append(issues, GenericTreeIssue[K]{ID: id, Kind: TreeIssueCycle})
+		}
+	}
+	if len(issues) > 0 {
+		return nil, &GenericTreeError[K]{Issues: issues}
+	}
+
+	// 构建树结构
+	for _, node := range ordered {
+		if node.ParentID == root {
+			continue
+		}
+		if parent, found := tree.nodes[node.ParentID]; found {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+	return tree, nil
+}
+
+// GetNode 获取指定 ID 的节点
+func (tree *GenericTree[K, V]) GetNode(nodeID K) (*GenericNode[K, V], bool) {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	node, found := tree.nodes[nodeID]
+	return node, found
+}
+
+// GetRootNodes 获取所有的根节点（ParentID 为零值），按输入顺序排列
+func (tree *GenericTree[K, V]) GetRootNodes() []*GenericNode[K, V] {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	return tree.rootNodes()
+}
+
+// rootNodes 获取按输入顺序排列的根节点（调用方需持有读锁）
+func (tree *GenericTree[K, V]) rootNodes() []*GenericNode[K, V] {
+	var root K
+	var rootNodes []*GenericNode[K, V]
+	for _, node := range tree.nodes {
+		if node.ParentID == root {
+			rootNodes = append(rootNodes, node)
+		}
+	}
+	slices.SortFunc(rootNodes, func(a, b *GenericNode[K, V]) int {
+		return cmp.Compare(tree.order[a.ID], tree.order[b.ID])
+	})
+	return rootNodes
+}
+
+// GetSubCategoryIDs 获取指定节点的所有子节点 ID（广度优先遍历）
+func (tree *GenericTree[K, V]) GetSubCategoryIDs(nodeID K, includeSelf bool) []K {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	startNode, found := tree.nodes[nodeID]
+	if !found {
+		return nil
+	}
+
+	subCategoryIDs := make([]K, 0)
+	if includeSelf {
+		subCategoryIDs = append(subCategoryIDs, startNode.ID)
+	}
+
+	// 广度优先遍历
+	queue := []*GenericNode[K, V]{startNode}
+	for len(queue) > 0 {
+		currentNode := queue[0]
+		queue = queue[1:]
+		for _, child := range currentNode.Children {
+			subCategoryIDs = append(subCategoryIDs, child.ID)
+			queue = append(queue, child)
+		}
+	}
+	return subCategoryIDs
+}
+
+// TreeLevel 获取某个节点的层级，节点不存在时返回 -1
+func (tree *GenericTree[K, V]) TreeLevel(nodeID K) int {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	currentNode, found := tree.nodes[nodeID]
+	if !found {
+		return -1
+	}
+
+	var root K
+	level := 0
+	for currentNode.ParentID != root {
+		level++
+		currentNode = tree.nodes[currentNode.ParentID]
+	}
+	return level
+}
+
+// IsParent 判断 parentID 是否是 nodeID 的直接父节点
+func (tree *GenericTree[K, V]) IsParent(nodeID, parentID K) bool {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	node, found := tree.nodes[nodeID]
+	if !found {
+		return false
+	}
+	return node.ParentID == parentID
+}
+
+// IsInSubTree 判断 targetID 是否位于 rootID 的子树中（包含 rootID 自身）
+func (tree *GenericTree[K, V]) IsInSubTree(rootID, targetID K) bool {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	if _, found := tree.nodes[rootID]; !found {
+		return false
+	}
+	currentNode, found := tree.nodes[targetID]
+	if !found {
+		return false
+	}
+
+	// 沿父节点向上查找，复杂度为 O(深度)
+	var root K
+	for {
+		if currentNode.ID == rootID {
+			return true
+		}
+		if currentNode.ParentID == root {
+			return false
+		}
+		currentNode = tree.nodes[currentNode.ParentID]
+	}
+}
+
+// TreeNodeValue TreeNode 在泛型树中的负载
+type TreeNodeValue struct {
+	Name     string `json:"name"`     // 节点名称
+	Selected bool   `json:"selected"` // 选中状态
+	Sorted   int    `json:"sorted"`   // 排序
+}
+
+// Generic 将 Tree 转换为等价的泛型树，根节点的 ParentID 统一转换为 0，根节点与子节点保持 Tree 的排序
+func (tree *Tree) Generic() *GenericTree[uint, TreeNodeValue] {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	generic := &GenericTree[uint, TreeNodeValue]{
+		nodes: make(map[uint]*GenericNode[uint, TreeNodeValue], len(tree.nodes)),
+		order: make(map[uint]int, len(tree.nodes)),
+	}
+	for i, root := range tree.rootNodes() {
+		generic.order[root.ID] = i
+	}
+	for id, node := range tree.nodes {
+		parentID := node.ParentID
+		if tree.isRoot(node) {
+			parentID = 0
+		}
+		generic.nodes[id] = &GenericNode[uint, TreeNodeValue]{
+			ID:       node.ID,
+			ParentID: parentID,
+			Value:    TreeNodeValue{Name: node.Name, Selected: node.Selected, Sorted: node.Sorted},
+		}
+	}
+	for id, node := range tree.nodes {
+		children := make([]*GenericNode[uint, TreeNodeValue], len(node.Children))
+		for i, child := range node.Children {
+			children[i] = generic.nodes[child.ID]
+		}
+		generic.nodes[id].Children = children
+	}
+	return generic
+}
+
+// NewTreeFromGeneric 从泛型树构建兼容的 Tree，泛型树的根节点按根节点规则设置 ParentID
+func NewTreeFromGeneric(generic *GenericTree[uint, TreeNodeValue], opts ...TreeOption) (*Tree, error) {
+	generic.mu.RLock()
+	defer generic.mu.RUnlock()
+
+	// 按先序遍历展开，保留泛型树中根节点与子节点的顺序
+	data := make([]TreeNode, 0, len(generic.nodes))
+	var roots []int
+	var walk func(node *GenericNode[uint, TreeNodeValue])
+	walk = func(node *GenericNode[uint, TreeNodeValue]) {
+		if node.ParentID == 0 {
+			roots = append(roots, len(data))
+		}
+		data = append(data, TreeNode{
+			ID:       node.ID,
+			ParentID: node.ParentID,
+			Name:     node.Value.Name,
+			Selected: node.Value.Selected,
+			Sorted:   node.Value.Sorted,
+		})
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	for _, root := range generic.rootNodes() {
+		walk(root)
+	}
+	return newTreeWithRoots(data, roots, opts)
+}
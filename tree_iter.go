@@ -0,0 +1,179 @@
+package ji
+
+import "iter"
+
+// WalkOption 树遍历的可选配置
+type WalkOption func(*walkConfig)
+
+// walkConfig 树遍历配置
+type walkConfig struct {
+	maxDepth int // 最大深度（相对起始节点），小于 0 表示不限制
+}
+
+// WithMaxDepth 限制遍历的最大深度，起始节点的深度为 0
+func WithMaxDepth(depth int) WalkOption {
+	return func(c *walkConfig) {
+		c.maxDepth = depth
+	}
+}
+
+// newWalkConfig 根据选项生成遍历配置
+func newWalkConfig(opts []WalkOption) walkConfig {
+	config := walkConfig{maxDepth: -1}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// within 判断深度是否在限制范围内
+func (c walkConfig) within(depth int) bool {
+	return c.maxDepth < 0 || depth <= c.maxDepth
+}
+
+// PreOrder 深度优先先序遍历
+// 参数:
+//   - startID: 起始节点 ID，为 0 且不存在 ID 为 0 的节点时从所有根节点开始
+//   - opts: 可选配置，例如 WithMaxDepth
+//
+// 遍历是惰性的：每次只在读锁下展开当前节点的子节点，释放锁后再返回节点，
+// 因此循环体内可以调用 Tree 的任意方法，提前 break 时不会继续遍历剩余节点；
+// 遍历期间对树的修改可能部分反映在本次遍历中，需要一致的视图时请遍历 Snapshot().Tree()
+func (tree *Tree) PreOrder(startID uint, opts ...WalkOption) iter.Seq[*TreeNode] {
+	config := newWalkConfig(opts)
+	return func(yield func(*TreeNode) bool) {
+		stack := pushFrames(nil, tree.startFrames(startID))
+		for len(stack) > 0 {
+			frame := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !yield(frame.node) {
+				return
+			}
+			if config.within(frame.depth + 1) {
+				stack = pushFrames(stack, tree.childFrames(frame))
+			}
+		}
+	}
+}
+
+// PostOrder 深度优先后序遍历，子节点先于父节点返回
+// 与 PreOrder 相同，遍历是惰性的，释放读锁后再返回节点
+func (tree *Tree) PostOrder(startID uint, opts ...WalkOption) iter.Seq[*TreeNode] {
+	config := newWalkConfig(opts)
+	return func(yield func(*TreeNode) bool) {
+		stack := pushFrames(nil, tree.startFrames(startID))
+		for len(stack) > 0 {
+			frame := stack[len(stack)-1]
+			if !frame.expanded && config.within(frame.depth+1) {
+				// 先展开子节点，子节点全部返回后再返回当前节点
+				stack[len(stack)-1].expanded = true
+				stack = pushFrames(stack, tree.childFrames(frame))
+				continue
+			}
+			stack = stack[:len(stack)-1]
+			if !yield(frame.node) {
+				return
+			}
+		}
+	}
+}
+
+// BreadthFirst 广度优先遍历
+// 与 PreOrder 相同，遍历是惰性的，释放读锁后再返回节点
+func (tree *Tree) BreadthFirst(startID uint, opts ...WalkOption) iter.Seq[*TreeNode] {
+	config := newWalkConfig(opts)
+	return func(yield func(*TreeNode) bool) {
+		queue := tree.startFrames(startID)
+		for len(queue) > 0 {
+			frame := queue[0]
+			queue = queue[1:]
+			if !yield(frame.node) {
+				return
+			}
+			if config.within(frame.depth + 1) {
+				queue = append(queue, tree.childFrames(frame)...)
+			}
+		}
+	}
+}
+
+// Levels 按层遍历，每次返回层级深度（起始节点为 0）及该层的所有节点
+// 每层在读锁下由上一层展开，释放锁后再返回，提前 break 时不会展开后续层级
+func (tree *Tree) Levels(startID uint, opts ...WalkOption) iter.Seq2[int, []*TreeNode] {
+	config := newWalkConfig(opts)
+	return func(yield func(int, []*TreeNode) bool) {
+		tree.mu.RLock()
+		level := tree.startNodes(startID)
+		tree.mu.RUnlock()
+
+		for depth := 0; len(level) > 0 && config.within(depth); depth++ {
+			if !yield(depth, level) {
+				return
+			}
+			level = tree.nextLevel(level)
+		}
+	}
+}
+
+// walkFrame 遍历中待访问的节点
+type walkFrame struct {
+	node     *TreeNode
+	depth    int  // 相对起始节点的深度
+	expanded bool // 后序遍历中子节点是否已入栈
+}
+
+// pushFrames 将 frames 逆序压入栈，使出栈顺序与 frames 的顺序一致
+func pushFrames(stack, frames []walkFrame) []walkFrame {
+	for i := len(frames) - 1; i >= 0; i-- {
+		stack = append(stack, frames[i])
+	}
+	return stack
+}
+
+// startFrames 在读锁下获取遍历的起始节点
+func (tree *Tree) startFrames(startID uint) []walkFrame {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	nodes := tree.startNodes(startID)
+	frames := make([]walkFrame, len(nodes))
+	for i, node := range nodes {
+		frames[i] = walkFrame{node: node}
+	}
+	return frames
+}
+
+// childFrames 在读锁下获取节点的子节点
+func (tree *Tree) childFrames(parent walkFrame) []walkFrame {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	frames := make([]walkFrame, len(parent.node.Children))
+	for i, child := range parent.node.Children {
+		frames[i] = walkFrame{node: child, depth: parent.depth + 1}
+	}
+	return frames
+}
+
+// nextLevel 在读锁下获取下一层的所有节点
+func (tree *Tree) nextLevel(level []*TreeNode) []*TreeNode {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	var next []*TreeNode
+	for _, node := range level {
+		next = append(next, node.Children...)
+	}
+	return next
+}
+
+// startNodes 获取遍历的起始节点，startID 为 0 且不存在 ID 为 0 的节点时返回所有根节点（调用方需持有读锁）
+func (tree *Tree) startNodes(startID uint) []*TreeNode {
+	if node, found := tree.nodes[startID]; found {
+		return []*TreeNode{node}
+	}
+	if startID == 0 {
+		return tree.rootNodes()
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package ji
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// NewTreeFromNested 从嵌套结构的节点构建树，ParentID 根据嵌套关系推断，顶层节点按根节点规则作为根节点
+// 重复的节点 ID 会以 *TreeError 的形式返回
+func NewTreeFromNested(roots []TreeNode, opts ...TreeOption) (*Tree, error) {
+	data, rootIndexes := flattenNested(roots)
+	return newTreeWithRoots(data, rootIndexes, opts)
+}
+
+// NewTreeFromJSON 从嵌套 JSON 构建树，支持节点数组或单个节点对象，
+// 例如前端拖拽排序后回传的树形数据
+func NewTreeFromJSON(data []byte, opts ...TreeOption) (*Tree, error) {
+	var roots []TreeNode
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var root TreeNode
+		if err := json.Unmarshal(trimmed, &root); err != nil {
+			return nil, fmt.Errorf("解析树形 JSON 失败: %w", err)
+		}
+		roots = []TreeNode{root}
+	} else if err := json.Unmarshal(data, &roots); err != nil {
+		return nil, fmt.Errorf("解析树形 JSON 失败: %w", err)
+	}
+	return NewTreeFromNested(roots, opts...)
+}
+
+// Flatten 将树展开为扁平的节点列表（先序遍历顺序，Children 为空），可直接传给 NewTree
+func (tree *Tree) Flatten() []TreeNode {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	data := make([]TreeNode, 0, len(tree.nodes))
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		flat := *node
+		flat.Children = nil
+		data = append(data, flat)
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	for _, root := range tree.rootNodes() {
+		walk(root)
+	}
+	return data
+}
+
+// MarshalJSON 将树序列化为根节点数组的嵌套 JSON
+func (tree *Tree) MarshalJSON() ([]byte, error) {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	roots := tree.rootNodes()
+	if roots == nil {
+		roots = []*TreeNode{}
+	}
+	return json.Marshal(roots)
+}
+
+// UnmarshalJSON 从嵌套 JSON 重建树，保留当前树的配置（例如排序规则）
+func (tree *Tree) UnmarshalJSON(data []byte) error {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	config := tree.config
+	parsed, err := NewTreeFromJSON(data, func(c *treeConfig) { *c = config })
+	if err != nil {
+		return err
+	}
+	tree.nodes = parsed.nodes
+	tree.assigned = parsed.assigned
+	tree.invalidate()
+	return nil
+}
+
+// flattenNested 先序展开嵌套节点，并根据嵌套关系设置 ParentID，同时返回顶层节点在结果中的下标
+func flattenNested(roots []TreeNode) ([]TreeNode, []int) {
+	var data []TreeNode
+	var rootIndexes []int
+	var walk func(node *TreeNode, parentID uint)
+	walk = func(node *TreeNode, parentID uint) {
+		flat := *node
+		flat.ParentID = parentID
+		flat.Children = nil
+		data = append(data, flat)
+		for _, child := range node.Children {
+			walk(child, node.ID)
+		}
+	}
+	for i := range roots {
+		rootIndexes = append(rootIndexes, len(data))
+		walk(&roots[i], 0)
+	}
+	return data, rootIndexes
+}
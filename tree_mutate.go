@@ -0,0 +1,230 @@
+package ji
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 树操作相关的错误
+var (
+	ErrTreeNodeNotFound = errors.New("树节点不存在")            // 指定的节点不存在
+	ErrTreeNodeExists   = errors.New("树节点已存在")            // 节点 ID 重复
+	ErrTreeCycle        = errors.New("不能将节点移动到其自身或子孙节点下") // 移动会产生环
+)
+
+// AddNode 向树中插入一个新节点
+// 参数:
+//   - node: 要插入的节点，ParentID 按根节点规则表示无父节点时（默认为 0）作为根节点插入，传入的 Children 会被忽略
+//
+// 返回值:
+//   - error: 节点 ID 已存在或父节点不存在时返回错误
+func (tree *Tree) AddNode(node TreeNode) error {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	return tree.addNode(node)
+}
+
+// addNode 见 AddNode（调用方需持有写锁）
+func (tree *Tree) addNode(node TreeNode) error {
+	if _, exists := tree.nodes[node.ID]; exists {
+		return fmt.Errorf("%w: %d", ErrTreeNodeExists, node.ID)
+	}
+	if tree.config.reservedID(node.ID) {
+		return fmt.Errorf("节点 ID %d 与根节点标记值冲突", node.ID)
+	}
+
+	newNode := node
+	newNode.Children = nil
+
+	// RootByMissingParent 规则下，ParentID 指向新节点的根节点会成为新节点的子节点
+	adopt := tree.config.rootRule.kind == rootByMissingParent
+	if adopt && newNode.ParentID == newNode.ID {
+		return fmt.Errorf("%w: %d -> %d", ErrTreeCycle, newNode.ID, newNode.ParentID)
+	}
+
+	if !tree.isRoot(&newNode) {
+		parent, found := tree.nodes[newNode.ParentID]
+		if !found {
+			return fmt.Errorf("%w: 父节点 %d", ErrTreeNodeNotFound, newNode.ParentID)
+		}
+		if adopt {
+			// 父节点所在树的根节点若指向新节点，收养后会形成环
+			root := parent
+			for !tree.isRoot(root) {
+				root = tree.nodes[root.ParentID]
+			}
+			if root.ParentID == newNode.ID && !tree.isAssigned(root.ID) {
+				return fmt.Errorf("%w: %d -> %d", ErrTreeCycle, newNode.ID, newNode.ParentID)
+			}
+		}
+		tree.attach(parent, &newNode)
+	}
+	tree.nodes[newNode.ID] = &newNode
+	if adopt {
+		for _, child := range tree.nodes {
+			if child == &newNode || child.ParentID != newNode.ID {
+				continue
+			}
+			if tree.isAssigned(child.ID) {
+				// 自动设置的 ParentID 不表示真实的父节点，重新选择不存在的 ID，保持其根节点身份
+				child.ParentID = tree.rootParentID(child.ID)
+				continue
+			}
+			tree.attach(&newNode, child)
+		}
+	}
+	tree.invalidate()
+	return nil
+}
+
+// MoveNode 将节点（连同其子树）移动到新的父节点下
+// 参数:
+//   - nodeID: 要移动的节点 ID
+//   - newParentID: 新的父节点 ID，按根节点规则表示无父节点时（默认为 0）移动为根节点
+//
+// 返回值:
+//   - error: 节点不存在，或新父节点是该节点自身及其子孙节点时返回错误
+func (tree *Tree) MoveNode(nodeID, newParentID uint) error {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	return tree.moveNode(nodeID, newParentID)
+}
+
+// moveNode 见 MoveNode（调用方需持有写锁）
+func (tree *Tree) moveNode(nodeID, newParentID uint) error {
+	node, found := tree.nodes[nodeID]
+	if !found {
+		return fmt.Errorf("%w: %d", ErrTreeNodeNotFound, nodeID)
+	}
+	if node.ParentID == newParentID {
+		return nil
+	}
+
+	var newParent *TreeNode
+	if !tree.isRootParent(nodeID, newParentID) {
+		newParent, found = tree.nodes[newParentID]
+		if !found {
+			return fmt.Errorf("%w: 父节点 %d", ErrTreeNodeNotFound, newParentID)
+		}
+		// 沿新父节点向上查找，若经过当前节点则说明会形成环
+		for current := newParent; current != nil; current = tree.nodes[current.ParentID] {
+			if current.ID == nodeID {
+				return fmt.Errorf("%w: %d -> %d", ErrTreeCycle, nodeID, newParentID)
+			}
+			if tree.isRoot(current) {
+				break
+			}
+		}
+	}
+
+	tree.detach(node)
+	node.ParentID = newParentID
+	delete(tree.assigned, nodeID)
+	if newParent != nil {
+		tree.attach(newParent, node)
+	}
+	tree.invalidate()
+	return nil
+}
+
+// DeleteNode 删除节点及其整个子树
+func (tree *Tree) DeleteNode(nodeID uint) error {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	return tree.deleteNode(nodeID)
+}
+
+// deleteNode 见 DeleteNode（调用方需持有写锁）
+func (tree *Tree) deleteNode(nodeID uint) error {
+	node, found := tree.nodes[nodeID]
+	if !found {
+		return fmt.Errorf("%w: %d", ErrTreeNodeNotFound, nodeID)
+	}
+
+	tree.detach(node)
+
+	// 深度优先删除子树中的所有节点
+	stack := []*TreeNode{node}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		delete(tree.nodes, current.ID)
+		delete(tree.assigned, current.ID)
+		stack = append(stack, current.Children...)
+	}
+	tree.invalidate()
+	return nil
+}
+
+// DeleteNodeKeepChildren 删除节点，并将其子节点提升到被删除节点的父节点下
+func (tree *Tree) DeleteNodeKeepChildren(nodeID uint) error {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	return tree.deleteNodeKeepChildren(nodeID)
+}
+
+// deleteNodeKeepChildren 见 DeleteNodeKeepChildren（调用方需持有写锁）
+func (tree *Tree) deleteNodeKeepChildren(nodeID uint) error {
+	node, found := tree.nodes[nodeID]
+	if !found {
+		return fmt.Errorf("%w: %d", ErrTreeNodeNotFound, nodeID)
+	}
+
+	tree.detach(node)
+	root := tree.isRoot(node)
+	parent := tree.nodes[node.ParentID]
+	for _, child := range node.Children {
+		if root {
+			// 被删除的节点此时仍在树中，不会被选为子节点的 ParentID
+			child.ParentID = tree.rootParentID(child.ID)
+			tree.markAssigned(child.ID)
+			continue
+		}
+		child.ParentID = node.ParentID
+		tree.attach(parent, child)
+	}
+	node.Children = nil
+	delete(tree.nodes, nodeID)
+	delete(tree.assigned, nodeID)
+	tree.invalidate()
+	return nil
+}
+
+// RenameNode 修改节点名称
+func (tree *Tree) RenameNode(nodeID uint, name string) error {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	return tree.renameNode(nodeID, name)
+}
+
+// renameNode 见 RenameNode（调用方需持有写锁）
+func (tree *Tree) renameNode(nodeID uint, name string) error {
+	node, found := tree.nodes[nodeID]
+	if !found {
+		return fmt.Errorf("%w: %d", ErrTreeNodeNotFound, nodeID)
+	}
+	node.Name = name
+	return nil
+}
+
+// detach 将节点从其父节点的 Children 中移除（调用方需持有写锁）
+func (tree *Tree) detach(node *TreeNode) {
+	if tree.isRoot(node) {
+		return
+	}
+	parent, found := tree.nodes[node.ParentID]
+	if !found {
+		return
+	}
+	for i, child := range parent.Children {
+		if child == node {
+			parent.Children = append(parent.Children[:i:i], parent.Children[i+1:]...)
+			return
+		}
+	}
+}
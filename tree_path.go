@@ -0,0 +1,101 @@
+package ji
+
+import "strings"
+
+// Ancestors 获取从根节点到指定节点的祖先链（根节点在前）
+// 参数:
+//   - nodeID: 节点 ID
+//   - includeSelf: 结果中是否包含节点自身
+//
+// 返回值:
+//   - []*TreeNode: 祖先节点列表，节点不存在时返回 nil
+func (tree *Tree) Ancestors(nodeID uint, includeSelf bool) []*TreeNode {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	path := tree.pathTo(nodeID)
+	if !includeSelf && len(path) > 0 {
+		path = path[:len(path)-1]
+	}
+	return path
+}
+
+// AncestorIDs 获取从根节点到指定节点的祖先 ID 链（根节点在前）
+func (tree *Tree) AncestorIDs(nodeID uint, includeSelf bool) []uint {
+	return MustSliceConvert(tree.Ancestors(nodeID, includeSelf), func(node *TreeNode) uint {
+		return node.ID
+	})
+}
+
+// PathName 获取从根节点到指定节点的名称路径，常用于面包屑，例如 "家电 / 电视 / 智能电视"
+// 参数:
+//   - nodeID: 节点 ID
+//   - sep: 名称之间的分隔符
+//
+// 返回值:
+//   - string: 拼接后的路径，节点不存在时返回空字符串
+func (tree *Tree) PathName(nodeID uint, sep string) string {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	path := tree.pathTo(nodeID)
+	names := make([]string, len(path))
+	for i, node := range path {
+		names[i] = node.Name
+	}
+	return strings.Join(names, sep)
+}
+
+// LowestCommonAncestor 获取多个节点的最近公共祖先（节点自身也可作为祖先）
+// 返回值:
+//   - *TreeNode: 最近公共祖先，任一节点不存在或节点位于不同的根下时返回 nil
+func (tree *Tree) LowestCommonAncestor(nodeIDs ...uint) *TreeNode {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	if len(nodeIDs) == 0 {
+		return nil
+	}
+
+	// 以第一个节点的路径为基准，依次与其他节点的路径求最长公共前缀
+	common := tree.pathTo(nodeIDs[0])
+	for _, nodeID := range nodeIDs[1:] {
+		path := tree.pathTo(nodeID)
+		n := min(len(common), len(path))
+		i := 0
+		for i < n && common[i] == path[i] {
+			i++
+		}
+		common = common[:i]
+		if len(common) == 0 {
+			return nil
+		}
+	}
+	if len(common) == 0 {
+		return nil
+	}
+	return common[len(common)-1]
+}
+
+// pathTo 获取从根节点到指定节点（包含自身）的路径，调用方需持有读锁
+func (tree *Tree) pathTo(nodeID uint) []*TreeNode {
+	node, found := tree.nodes[nodeID]
+	if !found {
+		return nil
+	}
+
+	var path []*TreeNode
+	for found {
+		path = append(path, node)
+		if tree.isRoot(node) {
+			break
+		}
+		node, found = tree.nodes[node.ParentID]
+	}
+
+	// 反转为根节点在前
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
@@ -0,0 +1,183 @@
+package ji
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TreeField 渲染节点标签时使用的字段，可按位组合
+type TreeField uint8
+
+// 可用于节点标签的字段
+const (
+	TreeFieldID       TreeField = 1 << iota // 节点 ID
+	TreeFieldName                           // 节点名称
+	TreeFieldSorted                         // 排序值
+	TreeFieldSelected                       // 选中状态
+)
+
+// RenderOption 树渲染的可选配置
+type RenderOption func(*renderConfig)
+
+// renderConfig 树渲染配置
+type renderConfig struct {
+	fields   TreeField                   // 标签字段
+	label    func(node *TreeNode) string // 自定义标签函数，优先于 fields
+	maxDepth int                         // 最大深度，根节点为 0，小于 0 表示不限制
+	ascii    bool                        // 文本大纲使用 ASCII 字符代替 Unicode 制表符
+}
+
+// WithRenderFields 指定节点标签包含的字段，默认为 TreeFieldID | TreeFieldName
+func WithRenderFields(fields TreeField) RenderOption {
+	return func(c *renderConfig) {
+		c.fields = fields
+	}
+}
+
+// WithRenderLabel 使用自定义函数生成节点标签
+func WithRenderLabel(label func(node *TreeNode) string) RenderOption {
+	return func(c *renderConfig) {
+		c.label = label
+	}
+}
+
+// WithRenderMaxDepth 限制渲染的最大深度，根节点的深度为 0
+func WithRenderMaxDepth(depth int) RenderOption {
+	return func(c *renderConfig) {
+		c.maxDepth = depth
+	}
+}
+
+// WithRenderASCII 文本大纲使用 ASCII 字符（|-- 与 `--）代替 Unicode 制表符
+func WithRenderASCII() RenderOption {
+	return func(c *renderConfig) {
+		c.ascii = true
+	}
+}
+
+// newRenderConfig 根据选项生成渲染配置
+func newRenderConfig(opts []RenderOption) renderConfig {
+	config := renderConfig{fields: TreeFieldID | TreeFieldName, maxDepth: -1}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// labelOf 生成节点标签
+func (c renderConfig) labelOf(node *TreeNode) string {
+	if c.label != nil {
+		return c.label(node)
+	}
+	var parts []string
+	if c.fields&TreeFieldID != 0 {
+		parts = append(parts, strconv.FormatUint(uint64(node.ID), 10))
+	}
+	if c.fields&TreeFieldName != 0 {
+		parts = append(parts, node.Name)
+	}
+	if c.fields&TreeFieldSorted != 0 {
+		parts = append(parts, fmt.Sprintf("sorted=%d", node.Sorted))
+	}
+	if c.fields&TreeFieldSelected != 0 {
+		parts = append(parts, fmt.Sprintf("selected=%t", node.Selected))
+	}
+	return strings.Join(parts, " ")
+}
+
+// within 判断深度是否在限制范围内
+func (c renderConfig) within(depth int) bool {
+	return c.maxDepth < 0 || depth <= c.maxDepth
+}
+
+// RenderText 渲染为类似 tree(1) 命令的文本大纲，便于调试
+//
+//	1 Root
+//	├── 2 Child1
+//	│   └── 4 Child1.1
+//	└── 3 Child2
+func (tree *Tree) RenderText(opts ...RenderOption) string {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	config := newRenderConfig(opts)
+	branch, last, pipe, space := "├── ", "└── ", "│   ", "    "
+	if config.ascii {
+		branch, last, pipe = "|-- ", "`-- ", "|   "
+	}
+
+	var builder strings.Builder
+	var walk func(node *TreeNode, prefix string, depth int)
+	walk = func(node *TreeNode, prefix string, depth int) {
+		if !config.within(depth + 1) {
+			return
+		}
+		for i, child := range node.Children {
+			connector, indent := branch, pipe
+			if i == len(node.Children)-1 {
+				connector, indent = last, space
+			}
+			builder.WriteString(prefix + connector + config.labelOf(child) + "\n")
+			walk(child, prefix+indent, depth+1)
+		}
+	}
+	for _, root := range tree.rootNodes() {
+		builder.WriteString(config.labelOf(root) + "\n")
+		walk(root, "", 0)
+	}
+	return builder.String()
+}
+
+// RenderDOT 渲染为 Graphviz DOT 格式
+func (tree *Tree) RenderDOT(opts ...RenderOption) string {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	config := newRenderConfig(opts)
+	var builder strings.Builder
+	builder.WriteString("digraph tree {\n")
+	builder.WriteString("  node [shape=box];\n")
+	tree.renderGraph(config, func(node *TreeNode) {
+		builder.WriteString(fmt.Sprintf("  n%d [label=%s];\n", node.ID, strconv.Quote(config.labelOf(node))))
+	}, func(parent, child *TreeNode) {
+		builder.WriteString(fmt.Sprintf("  n%d -> n%d;\n", parent.ID, child.ID))
+	})
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// RenderMermaid 渲染为 Mermaid 流程图格式
+func (tree *Tree) RenderMermaid(opts ...RenderOption) string {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	config := newRenderConfig(opts)
+	var builder strings.Builder
+	builder.WriteString("graph TD\n")
+	tree.renderGraph(config, func(node *TreeNode) {
+		label := strings.ReplaceAll(config.labelOf(node), `"`, "#quot;")
+		builder.WriteString(fmt.Sprintf("  n%d[\"%s\"]\n", node.ID, label))
+	}, func(parent, child *TreeNode) {
+		builder.WriteString(fmt.Sprintf("  n%d --> n%d\n", parent.ID, child.ID))
+	})
+	return builder.String()
+}
+
+// renderGraph 按先序遍历依次输出节点与边（调用方需持有读锁）
+func (tree *Tree) renderGraph(config renderConfig, writeNode func(node *TreeNode), writeEdge func(parent, child *TreeNode)) {
+	var walk func(node *TreeNode, depth int)
+	walk = func(node *TreeNode, depth int) {
+		writeNode(node)
+		if !config.within(depth + 1) {
+			return
+		}
+		for _, child := range node.Children {
+			writeEdge(node, child)
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range tree.rootNodes() {
+		walk(root, 0)
+	}
+}
@@ -0,0 +1,210 @@
+package ji
+
+import "fmt"
+
+// rootKind 根节点判定方式
+type rootKind uint8
+
+// 根节点判定方式
+const (
+	rootBySentinel      rootKind = iota // ParentID 等于标记值
+	rootBySelfParent                    // ParentID 等于自身 ID
+	rootByMissingParent                 // ParentID 指向不存在的节点
+)
+
+// TreeRootRule 根节点判定规则，零值等价于 RootBySentinel(0)
+type TreeRootRule struct {
+	kind     rootKind
+	sentinel uint
+}
+
+// RootBySentinel ParentID 等于 sentinel 的节点为根节点，ID 等于 sentinel 的节点不允许存在（默认规则，sentinel 为 0）
+func RootBySentinel(sentinel uint) TreeRootRule {
+	return TreeRootRule{kind: rootBySentinel, sentinel: sentinel}
+}
+
+// RootBySelfParent ParentID 等于自身 ID 的节点为根节点，适用于以自身表示“无父节点”的数据表
+func RootBySelfParent() TreeRootRule {
+	return TreeRootRule{kind: rootBySelfParent}
+}
+
+// RootByMissingParent ParentID 指向不存在节点的节点为根节点，此时 ID 为 0 的节点也可以正常存在；
+// 之后新增的节点 ID 若等于某些根节点的 ParentID，这些根节点会成为它的子节点。
+// 由树自动设置 ParentID 的根节点（导入时的顶层节点、DeleteNodeKeepChildren 提升的子节点、AttachVirtualRoot 的虚拟根节点）
+// 不会被收养，新增节点与其 ParentID 相同时会为其重新选择不存在的 ID
+func RootByMissingParent() TreeRootRule {
+	return TreeRootRule{kind: rootByMissingParent}
+}
+
+// WithRootRule 指定根节点判定规则，默认为 RootBySentinel(0)
+func WithRootRule(rule TreeRootRule) TreeOption {
+	return func(c *treeConfig) {
+		c.rootRule = rule
+	}
+}
+
+// rootParentID 将节点设为根节点时使用的 ParentID
+func (c *treeConfig) rootParentID(nodeID uint) uint {
+	switch c.rootRule.kind {
+	case rootBySelfParent:
+		return nodeID
+	case rootByMissingParent:
+		return 0
+	default:
+		return c.rootRule.sentinel
+	}
+}
+
+// setRootParents 为 data 中下标为 roots 的根节点设置 ParentID，
+// RootByMissingParent 规则下使用 data 中不存在的 ID，避免根节点被挂到 ID 为 0 的节点下
+func (c *treeConfig) setRootParents(data []TreeNode, roots []int) {
+	missing := uint(0)
+	if c.rootRule.kind == rootByMissingParent {
+		ids := make(map[uint]struct{}, len(data))
+		for _, node := range data {
+			ids[node.ID] = struct{}{}
+		}
+		missing = missingID(func(id uint) bool {
+			_, found := ids[id]
+			return found
+		})
+	}
+	for _, i := range roots {
+		if c.rootRule.kind == rootByMissingParent {
+			data[i].ParentID = missing
+			continue
+		}
+		data[i].ParentID = c.rootParentID(data[i].ID)
+	}
+}
+
+// newTreeWithRoots 为 data 中下标为 roots 的根节点设置 ParentID 后构建树，
+// RootByMissingParent 规则下这些根节点会被记录为自动设置 ParentID 的根节点
+func newTreeWithRoots(data []TreeNode, roots []int, opts []TreeOption) (*Tree, error) {
+	config := newTreeConfig(opts)
+	config.setRootParents(data, roots)
+	tree, err := NewTree(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range roots {
+		tree.markAssigned(data[i].ID)
+	}
+	return tree, nil
+}
+
+// markAssigned RootByMissingParent 规则下记录 ParentID 由树自动设置的根节点（调用方需持有写锁）
+func (tree *Tree) markAssigned(nodeID uint) {
+	if tree.config.rootRule.kind != rootByMissingParent {
+		return
+	}
+	if tree.assigned == nil {
+		tree.assigned = make(map[uint]struct{})
+	}
+	tree.assigned[nodeID] = struct{}{}
+}
+
+// isAssigned 判断节点的 ParentID 是否由树自动设置（调用方需持有读锁）
+func (tree *Tree) isAssigned(nodeID uint) bool {
+	_, found := tree.assigned[nodeID]
+	return found
+}
+
+// missingID 获取最小的不存在的 ID
+func missingID(exists func(id uint) bool) uint {
+	id := uint(0)
+	for exists(id) {
+		id++
+	}
+	return id
+}
+
+// reservedID 判断 ID 是否被根节点标记值占用
+func (c *treeConfig) reservedID(nodeID uint) bool {
+	return c.rootRule.kind == rootBySentinel && nodeID == c.rootRule.sentinel
+}
+
+// isRootParent 按根节点规则判断 parentID 是否表示节点 nodeID 没有父节点（调用方需持有读锁）
+func (tree *Tree) isRootParent(nodeID, parentID uint) bool {
+	switch tree.config.rootRule.kind {
+	case rootBySelfParent:
+		return parentID == nodeID
+	case rootByMissingParent:
+		_, found := tree.nodes[parentID]
+		return !found
+	default:
+		return parentID == tree.config.rootRule.sentinel
+	}
+}
+
+// rootParentID 将节点设为根节点时使用的 ParentID，
+// RootByMissingParent 规则下使用树中不存在且不等于 nodeID 的 ID（调用方需持有读锁）
+func (tree *Tree) rootParentID(nodeID uint) uint {
+	if tree.config.rootRule.kind == rootByMissingParent {
+		return missingID(func(id uint) bool {
+			_, found := tree.nodes[id]
+			return found || id == nodeID
+		})
+	}
+	return tree.config.rootParentID(nodeID)
+}
+
+// isRoot 判断节点是否为根节点（调用方需持有读锁）
+func (tree *Tree) isRoot(node *TreeNode) bool {
+	return tree.isRootParent(node.ID, node.ParentID)
+}
+
+// SplitForest 将森林按根节点拆分为多棵独立的树，每棵树都是深拷贝并沿用当前配置
+func (tree *Tree) SplitForest() []*Tree {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	roots := tree.rootNodes()
+	forest := make([]*Tree, 0, len(roots))
+	for _, root := range roots {
+		part := &Tree{nodes: make(map[uint]*TreeNode), config: tree.config}
+		var clone func(node *TreeNode) *TreeNode
+		clone = func(node *TreeNode) *TreeNode {
+			copied := *node
+			copied.Children = make([]*TreeNode, len(node.Children))
+			for i, child := range node.Children {
+				copied.Children[i] = clone(child)
+			}
+			part.nodes[copied.ID] = &copied
+			if tree.isAssigned(copied.ID) {
+				part.markAssigned(copied.ID)
+			}
+			return &copied
+		}
+		clone(root)
+		forest = append(forest, part)
+	}
+	return forest
+}
+
+// AttachVirtualRoot 插入一个虚拟根节点，并将现有的所有根节点移动到其下，
+// root.ParentID 会按根节点规则重新设置
+func (tree *Tree) AttachVirtualRoot(root TreeNode) error {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	if _, exists := tree.nodes[root.ID]; exists {
+		return fmt.Errorf("%w: %d", ErrTreeNodeExists, root.ID)
+	}
+	if tree.config.reservedID(root.ID) {
+		return fmt.Errorf("虚拟根节点 ID %d 与根节点标记值冲突", root.ID)
+	}
+
+	roots := tree.rootNodes()
+	root.ParentID = tree.rootParentID(root.ID)
+	if err := tree.addNode(root); err != nil {
+		return err
+	}
+	tree.markAssigned(root.ID)
+	for _, node := range roots {
+		if err := tree.moveNode(node.ID, root.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
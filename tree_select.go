@@ -0,0 +1,154 @@
+package ji
+
+import "fmt"
+
+// CheckState 树形复选框的选中状态
+type CheckState int
+
+// 复选框选中状态
+const (
+	Unchecked   CheckState = iota // 未选中
+	HalfChecked                   // 半选：部分子孙节点被选中
+	Checked                       // 全选
+)
+
+// SelectNode 选中或取消选中节点，并联动更新子树与祖先节点
+// 子树中的所有节点与该节点保持一致；祖先节点在其全部子节点被选中时才视为选中
+func (tree *Tree) SelectNode(nodeID uint, selected bool) error {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	node, found := tree.nodes[nodeID]
+	if !found {
+		return fmt.Errorf("%w: %d", ErrTreeNodeNotFound, nodeID)
+	}
+
+	// 向下联动子树
+	stack := []*TreeNode{node}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		current.Selected = selected
+		stack = append(stack, current.Children...)
+	}
+
+	// 向上联动祖先
+	for !tree.isRoot(node) {
+		parent, found := tree.nodes[node.ParentID]
+		if !found {
+			break
+		}
+		parent.Selected = allChildrenSelected(parent)
+		node = parent
+	}
+	return nil
+}
+
+// RefreshSelection 根据叶子节点的选中状态自底向上重新计算所有非叶子节点的 Selected，
+// 适用于从数据库加载的选中状态不一致时
+func (tree *Tree) RefreshSelection() {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	var refresh func(node *TreeNode)
+	refresh = func(node *TreeNode) {
+		if len(node.Children) == 0 {
+			return
+		}
+		for _, child := range node.Children {
+			refresh(child)
+		}
+		node.Selected = allChildrenSelected(node)
+	}
+	for _, root := range tree.rootNodes() {
+		refresh(root)
+	}
+}
+
+// CheckState 获取节点的复选框状态，节点不存在时返回 Unchecked
+func (tree *Tree) CheckState(nodeID uint) CheckState {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	node, found := tree.nodes[nodeID]
+	if !found {
+		return Unchecked
+	}
+	return checkState(node)
+}
+
+// HalfCheckedIDs 获取所有半选状态的节点 ID（先序遍历顺序）
+func (tree *Tree) HalfCheckedIDs() []uint {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	var ids []uint
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		if node.Selected {
+			return
+		}
+		if checkState(node) == HalfChecked {
+			ids = append(ids, node.ID)
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	for _, root := range tree.rootNodes() {
+		walk(root)
+	}
+	return ids
+}
+
+// MinimalSelectedIDs 获取最小的选中节点 ID 集合：完全选中的子树只返回其根节点
+func (tree *Tree) MinimalSelectedIDs() []uint {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+
+	var ids []uint
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		if node.Selected {
+			ids = append(ids, node.ID)
+			return
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	for _, root := range tree.rootNodes() {
+		walk(root)
+	}
+	return ids
+}
+
+// checkState 计算节点的复选框状态
+func checkState(node *TreeNode) CheckState {
+	if node.Selected {
+		return Checked
+	}
+	stack := append([]*TreeNode(nil), node.Children...)
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current.Selected {
+			return HalfChecked
+		}
+		stack = append(stack, current.Children...)
+	}
+	return Unchecked
+}
+
+// allChildrenSelected 判断节点的子节点是否全部被选中，没有子节点时保持原状态
+func allChildrenSelected(node *TreeNode) bool {
+	if len(node.Children) == 0 {
+		return node.Selected
+	}
+	for _, child := range node.Children {
+		if !child.Selected {
+			return false
+		}
+	}
+	return true
+}
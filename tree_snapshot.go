@@ -0,0 +1,169 @@
+package ji
+
+import (
+	"encoding/json"
+	"slices"
+	"sync/atomic"
+)
+
+// Clone 深拷贝整棵树，新树与原树不共享任何节点
+func (tree *Tree) Clone() *Tree {
+	return tree.Filter(func(*TreeNode) bool { return true })
+}
+
+// TreeSnapshot 树的只读快照
+// 快照创建后不会再被修改，读取时不会与树的写操作互相阻塞；
+// 所有方法返回的都是节点副本，调用方修改返回值不会影响快照
+type TreeSnapshot struct {
+	tree *Tree // 快照内部持有的深拷贝，永不修改
+}
+
+// Snapshot 创建当前树的只读快照
+func (tree *Tree) Snapshot() *TreeSnapshot {
+	return &TreeSnapshot{tree: tree.Clone()}
+}
+
+// Len 获取节点数量
+func (s *TreeSnapshot) Len() int {
+	return len(s.tree.nodes)
+}
+
+// Node 获取节点副本（不含 Children）
+func (s *TreeSnapshot) Node(nodeID uint) (TreeNode, bool) {
+	node, found := s.tree.nodes[nodeID]
+	if !found {
+		return TreeNode{}, false
+	}
+	return flatCopy(node), true
+}
+
+// RootNodes 获取所有根节点的副本（不含 Children），按排序规则排列
+func (s *TreeSnapshot) RootNodes() []TreeNode {
+	return MustSliceConvert(s.tree.rootNodes(), flatCopy)
+}
+
+// Children 获取节点直接子节点的副本（不含 Children）
+func (s *TreeSnapshot) Children(nodeID uint) []TreeNode {
+	node, found := s.tree.nodes[nodeID]
+	if !found {
+		return nil
+	}
+	return MustSliceConvert(node.Children, flatCopy)
+}
+
+// SubTree 获取以指定节点为根的子树的深拷贝（包含 Children）
+func (s *TreeSnapshot) SubTree(nodeID uint) (TreeNode, bool) {
+	node, found := s.tree.nodes[nodeID]
+	if !found {
+		return TreeNode{}, false
+	}
+	return deepCopy(node), true
+}
+
+// GetSubCategoryIDs 获取指定节点的所有子节点 ID，见 Tree.GetSubCategoryIDs
+func (s *TreeSnapshot) GetSubCategoryIDs(nodeID uint, includeSelf bool) []uint {
+	return s.tree.GetSubCategoryIDs(nodeID, includeSelf)
+}
+
+// TreeLevel 获取节点的层级，见 Tree.TreeLevel
+func (s *TreeSnapshot) TreeLevel(nodeID uint) int {
+	return s.tree.TreeLevel(nodeID)
+}
+
+// IsParent 判断 parentID 是否是 nodeID 的直接父节点
+func (s *TreeSnapshot) IsParent(nodeID, parentID uint) bool {
+	return s.tree.IsParent(nodeID, parentID)
+}
+
+// IsInSubTree 判断 targetID 是否位于 rootID 的子树中（包含 rootID 自身）
+func (s *TreeSnapshot) IsInSubTree(rootID, targetID uint) bool {
+	return s.tree.IsInSubTree(rootID, targetID)
+}
+
+// AncestorIDs 获取从根节点到指定节点的祖先 ID 链，见 Tree.AncestorIDs
+func (s *TreeSnapshot) AncestorIDs(nodeID uint, includeSelf bool) []uint {
+	return s.tree.AncestorIDs(nodeID, includeSelf)
+}
+
+// PathName 获取从根节点到指定节点的名称路径，见 Tree.PathName
+func (s *TreeSnapshot) PathName(nodeID uint, sep string) string {
+	return s.tree.PathName(nodeID, sep)
+}
+
+// Flatten 将快照展开为扁平的节点列表，见 Tree.Flatten
+func (s *TreeSnapshot) Flatten() []TreeNode {
+	return s.tree.Flatten()
+}
+
+// Tree 获取快照的可修改副本
+func (s *TreeSnapshot) Tree() *Tree {
+	return s.tree.Clone()
+}
+
+// MarshalJSON 将快照序列化为根节点数组的嵌套 JSON
+func (s *TreeSnapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.tree)
+}
+
+// AtomicTree 持有树的当前快照，读取无锁，重新加载时原子替换
+// 适用于请求处理中频繁读取、后台任务定期重新加载的场景
+type AtomicTree struct {
+	current atomic.Pointer[TreeSnapshot]
+}
+
+// NewAtomicTree 使用给定的树创建 AtomicTree，tree 为 nil 时 Load 返回 nil
+func NewAtomicTree(tree *Tree) *AtomicTree {
+	a := &AtomicTree{}
+	if tree != nil {
+		a.Store(tree)
+	}
+	return a
+}
+
+// Load 获取当前快照
+func (a *AtomicTree) Load() *TreeSnapshot {
+	return a.current.Load()
+}
+
+// Store 为 tree 创建快照并原子替换当前快照
+func (a *AtomicTree) Store(tree *Tree) {
+	a.current.Store(tree.Snapshot())
+}
+
+// Reload 调用 load 获取最新数据并构建新树，构建成功后原子替换当前快照；
+// 失败时保留旧快照并返回错误
+func (a *AtomicTree) Reload(load func() ([]TreeNode, error), opts ...TreeOption) error {
+	data, err := load()
+	if err != nil {
+		return err
+	}
+	// NewTree 会直接引用切片中的元素，复制一份避免调用方修改 data 影响快照
+	data = slices.Clone(data)
+	for i := range data {
+		data[i].Children = nil
+	}
+	tree, err := NewTree(data, opts...)
+	if err != nil {
+		return err
+	}
+	a.current.Store(&TreeSnapshot{tree: tree})
+	return nil
+}
+
+// flatCopy 复制节点（不含 Children）
+func flatCopy(node *TreeNode) TreeNode {
+	copied := *node
+	copied.Children = nil
+	return copied
+}
+
+// deepCopy 深拷贝节点及其子树
+func deepCopy(node *TreeNode) TreeNode {
+	copied := *node
+	copied.Children = make([]*TreeNode, len(node.Children))
+	for i, child := range node.Children {
+		childCopy := deepCopy(child)
+		copied.Children[i] = &childCopy
+	}
+	return copied
+}
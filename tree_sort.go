@@ -0,0 +1,95 @@
+package ji
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// TreeCompareFunc 兄弟节点的比较函数，返回负数表示 a 排在 b 之前
+type TreeCompareFunc func(a, b *TreeNode) int
+
+// CompareTreeNode 默认的兄弟节点比较函数：先按 Sorted 升序，再按 ID 升序
+func CompareTreeNode(a, b *TreeNode) int {
+	if c := cmp.Compare(a.Sorted, b.Sorted); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// WithTreeCompare 指定兄弟节点及根节点的排序规则，默认使用 CompareTreeNode
+func WithTreeCompare(compare TreeCompareFunc) TreeOption {
+	return func(c *treeConfig) {
+		c.compare = compare
+	}
+}
+
+// SetCompare 替换排序规则并立即重新排序整棵树，传入 nil 时恢复默认规则
+func (tree *Tree) SetCompare(compare TreeCompareFunc) {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	tree.config.compare = compare
+	tree.sortAll()
+}
+
+// Sort 按当前排序规则重新排序所有节点的子节点，
+// 适用于直接修改了节点字段（例如 Sorted）之后
+func (tree *Tree) Sort() {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	tree.sortAll()
+}
+
+// SetSorted 修改节点的 Sorted 值，并调整其在兄弟节点中的位置
+func (tree *Tree) SetSorted(nodeID uint, sorted int) error {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	return tree.setSorted(nodeID, sorted)
+}
+
+// setSorted 见 SetSorted（调用方需持有写锁）
+func (tree *Tree) setSorted(nodeID uint, sorted int) error {
+	node, found := tree.nodes[nodeID]
+	if !found {
+		return fmt.Errorf("%w: %d", ErrTreeNodeNotFound, nodeID)
+	}
+	if node.Sorted == sorted {
+		return nil
+	}
+
+	tree.detach(node)
+	node.Sorted = sorted
+	if parent, found := tree.nodes[node.ParentID]; found && !tree.isRoot(node) {
+		tree.attach(parent, node)
+	}
+	return nil
+}
+
+// compareFunc 返回当前生效的比较函数
+func (c *treeConfig) compareFunc() TreeCompareFunc {
+	if c.compare != nil {
+		return c.compare
+	}
+	return CompareTreeNode
+}
+
+// sortAll 对所有节点的子节点排序（调用方需持有写锁）
+func (tree *Tree) sortAll() {
+	compare := tree.config.compareFunc()
+	for _, node := range tree.nodes {
+		slices.SortStableFunc(node.Children, compare)
+	}
+}
+
+// attach 按排序规则将子节点插入父节点的 Children 中（调用方需持有写锁）
+func (tree *Tree) attach(parent, child *TreeNode) {
+	compare := tree.config.compareFunc()
+	i := len(parent.Children)
+	for i > 0 && compare(child, parent.Children[i-1]) < 0 {
+		i--
+	}
+	parent.Children = slices.Insert(parent.Children, i, child)
+}
@@ -0,0 +1,111 @@
+package ji
+
+import "maps"
+
+// TreeStats 节点子树的统计信息
+type TreeStats struct {
+	DescendantCount int `json:"descendant_count"` // 子孙节点数量（不含自身）
+	LeafCount       int `json:"leaf_count"`       // 子树中的叶子节点数量（叶子节点自身计为 1）
+	Height          int `json:"height"`           // 子树高度，叶子节点为 0
+}
+
+// treeCache 子树聚合结果缓存，树结构变更时清空
+type treeCache struct {
+	stats   map[uint]TreeStats          // 节点统计信息
+	rollups map[string]map[uint]float64 // 指标名称 -> 节点 ID -> 子树合计
+	metrics map[string]map[uint]float64 // 指标名称 -> 节点 ID -> 节点自身的值（不随变更清空）
+}
+
+// Stats 获取节点子树的统计信息，首次调用时通过一次后序遍历计算全部节点并缓存，直到树结构被修改
+func (tree *Tree) Stats(nodeID uint) (TreeStats, bool) {
+	tree.mu.RLock()
+	if tree.cache.stats != nil {
+		stats, found := tree.cache.stats[nodeID]
+		tree.mu.RUnlock()
+		return stats, found
+	}
+	tree.mu.RUnlock()
+
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	if tree.cache.stats == nil {
+		tree.cache.stats = make(map[uint]TreeStats, len(tree.nodes))
+		var walk func(node *TreeNode) TreeStats
+		walk = func(node *TreeNode) TreeStats {
+			var stats TreeStats
+			for _, child := range node.Children {
+				childStats := walk(child)
+				stats.DescendantCount += childStats.DescendantCount + 1
+				stats.LeafCount += childStats.LeafCount
+				stats.Height = max(stats.Height, childStats.Height+1)
+			}
+			if len(node.Children) == 0 {
+				stats.LeafCount = 1
+			}
+			tree.cache.stats[node.ID] = stats
+			return stats
+		}
+		for _, root := range tree.rootNodes() {
+			walk(root)
+		}
+	}
+	stats, found := tree.cache.stats[nodeID]
+	return stats, found
+}
+
+// SetMetric 设置一个按节点 ID 记录的指标（例如每个分类下直接挂载的商品数），
+// 之后可通过 MetricTotal 查询包含子孙节点在内的合计值；values 会被复制，之后修改 values 不影响已设置的指标
+func (tree *Tree) SetMetric(name string, values map[uint]float64) {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	if tree.cache.metrics == nil {
+		tree.cache.metrics = make(map[string]map[uint]float64)
+	}
+	tree.cache.metrics[name] = maps.Clone(values)
+	delete(tree.cache.rollups, name)
+}
+
+// MetricTotal 获取指标在节点子树上的合计值（包含节点自身）
+// 首次查询某个指标时通过一次后序遍历计算全部节点并缓存，直到树结构被修改或重新设置该指标
+func (tree *Tree) MetricTotal(name string, nodeID uint) float64 {
+	tree.mu.RLock()
+	if totals, found := tree.cache.rollups[name]; found {
+		tree.mu.RUnlock()
+		return totals[nodeID]
+	}
+	tree.mu.RUnlock()
+
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+
+	totals, found := tree.cache.rollups[name]
+	if !found {
+		values := tree.cache.metrics[name]
+		totals = make(map[uint]float64, len(tree.nodes))
+		var walk func(node *TreeNode) float64
+		walk = func(node *TreeNode) float64 {
+			total := values[node.ID]
+			for _, child := range node.Children {
+				total += walk(child)
+			}
+			totals[node.ID] = total
+			return total
+		}
+		for _, root := range tree.rootNodes() {
+			walk(root)
+		}
+		if tree.cache.rollups == nil {
+			tree.cache.rollups = make(map[string]map[uint]float64)
+		}
+		tree.cache.rollups[name] = totals
+	}
+	return totals[nodeID]
+}
+
+// invalidate 树结构变更后清空聚合缓存（调用方需持有写锁）
+func (tree *Tree) invalidate() {
+	tree.cache.stats = nil
+	tree.cache.rollups = nil
+}
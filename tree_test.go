@@ -1,9 +1,15 @@
 package ji
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // 打印树结构并以 JSON 格式输出
@@ -89,3 +95,1033 @@ func BenchmarkIsInSubTreeConcurrent(b *testing.B) {
 		tree.IsInSubTreeConcurrent(1, 4)
 	}
 }
+
+// isInSubTreeGoroutines 旧版 IsInSubTreeConcurrent 的实现（每个节点一个 goroutine），仅用于基准对比
+func isInSubTreeGoroutines(tree *Tree, rootID, targetID uint) bool {
+	tree.mu.RLock()
+	rootNode, found := tree.nodes[rootID]
+	tree.mu.RUnlock()
+	if !found {
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resultChan := make(chan bool, 1)
+	var search func(node *TreeNode)
+	search = func(node *TreeNode) {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		if node.ID == targetID {
+			select {
+			case resultChan <- true:
+			default:
+			}
+			return
+		}
+		var wg sync.WaitGroup
+		for _, child := range node.Children {
+			wg.Add(1)
+			go func(childNode *TreeNode) {
+				defer wg.Done()
+				search(childNode)
+			}(child)
+		}
+		wg.Wait()
+	}
+
+	go func() {
+		search(rootNode)
+		close(resultChan)
+	}()
+	return <-resultChan
+}
+
+// newBenchmarkTree 构建一棵包含 n 个节点、每个节点最多 8 个子节点的树
+func newBenchmarkTree(n int) *Tree {
+	data := make([]TreeNode, n)
+	for i := range data {
+		data[i] = TreeNode{ID: uint(i + 1), Name: fmt.Sprint("Node", i+1)}
+		if i > 0 {
+			data[i].ParentID = uint((i-1)/8 + 1)
+		}
+	}
+	tree, _ := NewTree(data)
+	return tree
+}
+
+func TestIsInSubTree(t *testing.T) {
+	tree := newBenchmarkTree(1000)
+	for _, c := range []struct {
+		rootID, targetID uint
+		expected         bool
+	}{
+		{1, 1000, true},
+		{2, 2, true},
+		{2, 17, true},
+		{2, 3, false},
+		{3, 1000, false},
+		{1, 9999, false},
+		{9999, 1, false},
+	} {
+		if got := tree.IsInSubTree(c.rootID, c.targetID); got != c.expected {
+			t.Fatalf("IsInSubTree(%d, %d) 期望为 %v，实际为 %v", c.rootID, c.targetID, c.expected, got)
+		}
+		if got := isInSubTreeGoroutines(tree, c.rootID, c.targetID); got != c.expected {
+			t.Fatalf("旧实现 (%d, %d) 期望为 %v，实际为 %v", c.rootID, c.targetID, c.expected, got)
+		}
+	}
+}
+
+// 性能基准测试 IsInSubTree（5 万节点）
+func BenchmarkIsInSubTree(b *testing.B) {
+	tree := newBenchmarkTree(50000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.IsInSubTree(1, 50000)
+	}
+}
+
+// 性能基准测试旧版 goroutine 实现（5 万节点），用于与 IsInSubTree 对比
+func BenchmarkIsInSubTreeGoroutines(b *testing.B) {
+	tree := newBenchmarkTree(50000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		isInSubTreeGoroutines(tree, 1, 50000)
+	}
+}
+
+func TestTreeMutations(t *testing.T) {
+	data := []TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 2, ParentID: 1, Name: "Child1"},
+		{ID: 3, ParentID: 1, Name: "Child2"},
+		{ID: 4, ParentID: 2, Name: "Child1.1"},
+	}
+	tree, _ := NewTree(data)
+
+	// 测试插入节点
+	if err := tree.AddNode(TreeNode{ID: 5, ParentID: 4, Name: "Child1.1.1"}); err != nil {
+		t.Fatalf("插入节点失败: %v", err)
+	}
+	if err := tree.AddNode(TreeNode{ID: 5, ParentID: 1}); !errors.Is(err, ErrTreeNodeExists) {
+		t.Fatalf("期望重复插入返回 ErrTreeNodeExists，实际为 %v", err)
+	}
+	if err := tree.AddNode(TreeNode{ID: 6, ParentID: 99}); !errors.Is(err, ErrTreeNodeNotFound) {
+		t.Fatalf("期望父节点不存在时返回 ErrTreeNodeNotFound，实际为 %v", err)
+	}
+	if level := tree.TreeLevel(5); level != 3 {
+		t.Fatalf("期望节点 5 的层级为 3，实际为 %v", level)
+	}
+
+	// 测试移动节点
+	if err := tree.MoveNode(2, 4); !errors.Is(err, ErrTreeCycle) {
+		t.Fatalf("期望移动到子孙节点下返回 ErrTreeCycle，实际为 %v", err)
+	}
+	if err := tree.MoveNode(2, 2); !errors.Is(err, ErrTreeCycle) {
+		t.Fatalf("期望移动到自身下返回 ErrTreeCycle，实际为 %v", err)
+	}
+	if err := tree.MoveNode(4, 3); err != nil {
+		t.Fatalf("移动节点失败: %v", err)
+	}
+	if !tree.IsParent(4, 3) || len(tree.nodes[2].Children) != 0 || len(tree.nodes[3].Children) != 1 {
+		t.Fatalf("移动后节点 4 应位于节点 3 下")
+	}
+
+	// 测试重命名
+	if err := tree.RenameNode(3, "Renamed"); err != nil || tree.nodes[3].Name != "Renamed" {
+		t.Fatalf("重命名节点失败: %v", err)
+	}
+
+	// 测试删除节点并提升子节点
+	if err := tree.DeleteNodeKeepChildren(3); err != nil {
+		t.Fatalf("删除节点失败: %v", err)
+	}
+	if !tree.IsParent(4, 1) || len(tree.nodes[1].Children) != 2 {
+		t.Fatalf("期望节点 4 被提升到节点 1 下")
+	}
+
+	// 测试删除子树
+	if err := tree.DeleteNode(4); err != nil {
+		t.Fatalf("删除子树失败: %v", err)
+	}
+	if ids := tree.GetSubCategoryIDs(1, true); len(ids) != 2 {
+		t.Fatalf("期望剩余 2 个节点，实际为 %v", ids)
+	}
+	if _, found := tree.nodes[5]; found {
+		t.Fatalf("期望节点 5 随子树一起被删除")
+	}
+}
+
+func TestNewTreeValidation(t *testing.T) {
+	data := []TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 2, ParentID: 1, Name: "Child1"},
+		{ID: 2, ParentID: 1, Name: "Duplicate"},
+		{ID: 3, ParentID: 3, Name: "Self"},
+		{ID: 4, ParentID: 5, Name: "CycleA"},
+		{ID: 5, ParentID: 4, Name: "CycleB"},
+		{ID: 6, ParentID: 99, Name: "Orphan"},
+	}
+
+	// 测试校验错误
+	_, err := NewTree(data)
+	var treeErr *TreeError
+	if !errors.As(err, &treeErr) {
+		t.Fatalf("期望返回 *TreeError，实际为 %v", err)
+	}
+	expected := []TreeIssue{
+		{ID: 2, Kind: TreeIssueDuplicateID},
+		{ID: 3, Kind: TreeIssueSelfParent},
+		{ID: 6, Kind: TreeIssueOrphan},
+		{ID: 4, Kind: TreeIssueCycle},
+		{ID: 5, Kind: TreeIssueCycle},
+	}
+	if !SliceEqual(treeErr.Issues, expected) {
+		t.Fatalf("期望问题列表为 %v，实际为 %v", expected, treeErr.Issues)
+	}
+
+	// 测试容忍孤儿节点
+	tree, err := NewTree([]TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 2, ParentID: 99, Name: "Orphan"},
+	}, WithOrphansAsRoots())
+	if err != nil {
+		t.Fatalf("期望容忍孤儿节点，实际返回错误: %v", err)
+	}
+	if roots := tree.GetRootNodes(); len(roots) != 2 {
+		t.Fatalf("期望孤儿节点被视为根节点，实际根节点为 %v", roots)
+	}
+	if level := tree.TreeLevel(2); level != 0 {
+		t.Fatalf("期望孤儿节点层级为 0，实际为 %v", level)
+	}
+}
+
+func TestGenericTree(t *testing.T) {
+	type region struct {
+		Title string
+	}
+	data := []GenericNode[string, region]{
+		{ID: "cn", ParentID: "", Value: region{Title: "中国"}},
+		{ID: "zj", ParentID: "cn", Value: region{Title: "浙江"}},
+		{ID: "hz", ParentID: "zj", Value: region{Title: "杭州"}},
+		{ID: "js", ParentID: "cn", Value: region{Title: "江苏"}},
+	}
+	tree, err := NewGenericTree(data)
+	if err != nil {
+		t.Fatalf("创建泛型树失败: %v", err)
+	}
+
+	// 测试查询方法
+	if roots := tree.GetRootNodes(); len(roots) != 1 || roots[0].ID != "cn" {
+		t.Fatalf("期望有 1 个根节点 cn，实际为 %v", roots)
+	}
+	if ids := tree.GetSubCategoryIDs("cn", false); !SliceEqual(ids, []string{"zj", "js", "hz"}) {
+		t.Fatalf("期望子节点为 [zj js hz]，实际为 %v", ids)
+	}
+	if level := tree.TreeLevel("hz"); level != 2 {
+		t.Fatalf("期望节点 hz 的层级为 2，实际为 %v", level)
+	}
+	if !tree.IsParent("hz", "zj") || !tree.IsInSubTree("cn", "hz") || tree.IsInSubTree("js", "hz") {
+		t.Fatalf("父子关系判断错误")
+	}
+	if node, found := tree.GetNode("hz"); !found || node.Value.Title != "杭州" {
+		t.Fatalf("期望获取到节点 hz，实际为 %v", node)
+	}
+
+	// 测试校验
+	_, err = NewGenericTree([]GenericNode[string, region]{{ID: "a", ParentID: "b"}, {ID: "b", ParentID: "a"}})
+	var treeErr *GenericTreeError[string]
+	if !errors.As(err, &treeErr) || len(treeErr.Issues) != 2 {
+		t.Fatalf("期望返回环校验错误，实际为 %v", err)
+	}
+
+	_, err = NewGenericTree([]GenericNode[string, region]{{ID: ""}, {ID: "a", ParentID: ""}})
+	if !errors.As(err, &treeErr) || len(treeErr.Issues) != 1 || treeErr.Issues[0].Kind != TreeIssueReservedID {
+		t.Fatalf("期望零值 ID 返回保留 ID 校验错误，实际为 %v", err)
+	}
+
+	// 测试根节点按输入顺序排列
+	forest, _ := NewGenericTree([]GenericNode[int, string]{{ID: 9}, {ID: 3}, {ID: 5}, {ID: 1, ParentID: 3}})
+	for i := 0; i < 10; i++ {
+		if roots := forest.GetRootNodes(); len(roots) != 3 || roots[0].ID != 9 || roots[1].ID != 3 || roots[2].ID != 5 {
+			t.Fatalf("期望根节点为 [9 3 5]，实际为 %v", roots)
+		}
+	}
+
+	// 测试不支持的选项
+	if _, err := NewGenericTree(data, WithRootRule(RootBySelfParent())); err == nil {
+		t.Fatal("期望 WithRootRule 返回错误")
+	}
+	if _, err := NewGenericTree(data, WithTreeCompare(CompareTreeNode)); err == nil {
+		t.Fatal("期望 WithTreeCompare 返回错误")
+	}
+
+	// 测试与 Tree 互相转换
+	compat, _ := NewTree([]TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 2, ParentID: 1, Name: "Child1"},
+	})
+	back, err := NewTreeFromGeneric(compat.Generic())
+	if err != nil || !back.IsParent(2, 1) || back.nodes[2].Name != "Child1" {
+		t.Fatalf("Tree 与泛型树转换失败: %v", err)
+	}
+	sorted, _ := NewTree([]TreeNode{{ID: 1, Sorted: 2}, {ID: 2, Sorted: 1}})
+	if roots := sorted.Generic().GetRootNodes(); roots[0].ID != 2 || roots[1].ID != 1 {
+		t.Fatalf("期望泛型树保持 Tree 的根节点排序 [2 1]，实际为 %v", roots)
+	}
+}
+
+func TestTreePath(t *testing.T) {
+	data := []TreeNode{
+		{ID: 1, ParentID: 0, Name: "家电"},
+		{ID: 2, ParentID: 1, Name: "电视"},
+		{ID: 3, ParentID: 2, Name: "智能电视"},
+		{ID: 4, ParentID: 2, Name: "投影"},
+		{ID: 5, ParentID: 1, Name: "冰箱"},
+		{ID: 6, ParentID: 0, Name: "服装"},
+	}
+	tree, _ := NewTree(data)
+
+	// 测试祖先链
+	if ids := tree.AncestorIDs(3, true); !SliceEqual(ids, []uint{1, 2, 3}) {
+		t.Fatalf("期望祖先链为 [1 2 3]，实际为 %v", ids)
+	}
+	if ids := tree.AncestorIDs(3, false); !SliceEqual(ids, []uint{1, 2}) {
+		t.Fatalf("期望祖先链为 [1 2]，实际为 %v", ids)
+	}
+	if nodes := tree.Ancestors(99, true); nodes != nil {
+		t.Fatalf("期望不存在的节点返回 nil，实际为 %v", nodes)
+	}
+
+	// 测试名称路径
+	if path := tree.PathName(3, " / "); path != "家电 / 电视 / 智能电视" {
+		t.Fatalf("期望路径为 家电 / 电视 / 智能电视，实际为 %v", path)
+	}
+
+	// 测试最近公共祖先
+	if lca := tree.LowestCommonAncestor(3, 4); lca == nil || lca.ID != 2 {
+		t.Fatalf("期望节点 3、4 的最近公共祖先为 2，实际为 %v", lca)
+	}
+	if lca := tree.LowestCommonAncestor(3, 4, 5); lca == nil || lca.ID != 1 {
+		t.Fatalf("期望节点 3、4、5 的最近公共祖先为 1，实际为 %v", lca)
+	}
+	if lca := tree.LowestCommonAncestor(2, 3); lca == nil || lca.ID != 2 {
+		t.Fatalf("期望节点 2、3 的最近公共祖先为 2，实际为 %v", lca)
+	}
+	if lca := tree.LowestCommonAncestor(3, 6); lca != nil {
+		t.Fatalf("期望不同根下的节点没有公共祖先，实际为 %v", lca)
+	}
+}
+
+func TestTreeSort(t *testing.T) {
+	data := []TreeNode{
+		{ID: 5, ParentID: 0, Name: "RootB", Sorted: 2},
+		{ID: 1, ParentID: 0, Name: "RootA", Sorted: 1},
+		{ID: 4, ParentID: 1, Name: "C", Sorted: 3},
+		{ID: 3, ParentID: 1, Name: "B", Sorted: 1},
+		{ID: 2, ParentID: 1, Name: "A", Sorted: 1},
+	}
+	tree, _ := NewTree(data)
+	childIDs := func(nodeID uint) []uint {
+		return MustSliceConvert(tree.nodes[nodeID].Children, func(node *TreeNode) uint { return node.ID })
+	}
+
+	// 测试默认排序：先 Sorted 后 ID
+	roots := tree.GetRootNodes()
+	if len(roots) != 2 || roots[0].ID != 1 || roots[1].ID != 5 {
+		t.Fatalf("期望根节点顺序为 [1 5]，实际为 %v", roots)
+	}
+	if ids := childIDs(1); !SliceEqual(ids, []uint{2, 3, 4}) {
+		t.Fatalf("期望子节点顺序为 [2 3 4]，实际为 %v", ids)
+	}
+
+	// 测试变更后保持有序
+	_ = tree.AddNode(TreeNode{ID: 6, ParentID: 1, Sorted: 2})
+	_ = tree.SetSorted(2, 9)
+	if ids := childIDs(1); !SliceEqual(ids, []uint{3, 6, 4, 2}) {
+		t.Fatalf("期望子节点顺序为 [3 6 4 2]，实际为 %v", ids)
+	}
+
+	// 测试自定义排序规则
+	tree.SetCompare(func(a, b *TreeNode) int {
+		return strings.Compare(b.Name, a.Name)
+	})
+	if ids := childIDs(1); !SliceEqual(ids, []uint{4, 3, 2, 6}) {
+		t.Fatalf("期望按名称倒序为 [4 3 2 6]，实际为 %v", ids)
+	}
+}
+
+func TestTreeSelection(t *testing.T) {
+	data := []TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 2, ParentID: 1, Name: "Child1"},
+		{ID: 3, ParentID: 1, Name: "Child2"},
+		{ID: 4, ParentID: 2, Name: "Child1.1"},
+		{ID: 5, ParentID: 2, Name: "Child1.2"},
+		{ID: 6, ParentID: 3, Name: "Child2.1"},
+	}
+	tree, _ := NewTree(data)
+
+	// 测试选中子树
+	_ = tree.SelectNode(2, true)
+	if !tree.nodes[4].Selected || !tree.nodes[5].Selected {
+		t.Fatalf("期望选中节点 2 时其子树被全部选中")
+	}
+	if state := tree.CheckState(1); state != HalfChecked {
+		t.Fatalf("期望节点 1 为半选状态，实际为 %v", state)
+	}
+	if ids := tree.MinimalSelectedIDs(); !SliceEqual(ids, []uint{2}) {
+		t.Fatalf("期望最小选中集合为 [2]，实际为 %v", ids)
+	}
+
+	// 测试子节点全选后父节点联动选中
+	_ = tree.SelectNode(6, true)
+	if state := tree.CheckState(1); state != Checked {
+		t.Fatalf("期望节点 1 为全选状态，实际为 %v", state)
+	}
+	if ids := tree.MinimalSelectedIDs(); !SliceEqual(ids, []uint{1}) {
+		t.Fatalf("期望最小选中集合为 [1]，实际为 %v", ids)
+	}
+
+	// 测试取消选中
+	_ = tree.SelectNode(4, false)
+	if tree.nodes[2].Selected || tree.nodes[1].Selected {
+		t.Fatalf("期望取消选中节点 4 后祖先节点不再全选")
+	}
+	if ids := tree.HalfCheckedIDs(); !SliceEqual(ids, []uint{1, 2}) {
+		t.Fatalf("期望半选节点为 [1 2]，实际为 %v", ids)
+	}
+	if ids := tree.MinimalSelectedIDs(); !SliceEqual(ids, []uint{5, 3}) {
+		t.Fatalf("期望最小选中集合为 [5 3]，实际为 %v", ids)
+	}
+
+	// 测试重新计算选中状态
+	tree.nodes[4].Selected = true
+	tree.RefreshSelection()
+	if state := tree.CheckState(1); state != Checked {
+		t.Fatalf("期望重新计算后节点 1 为全选状态，实际为 %v", state)
+	}
+}
+
+func TestTreeFilter(t *testing.T) {
+	data := []TreeNode{
+		{ID: 1, ParentID: 0, Name: "家电"},
+		{ID: 2, ParentID: 1, Name: "电视"},
+		{ID: 3, ParentID: 2, Name: "智能电视"},
+		{ID: 4, ParentID: 2, Name: "投影仪"},
+		{ID: 5, ParentID: 1, Name: "冰箱"},
+		{ID: 6, ParentID: 0, Name: "服装"},
+	}
+	tree, _ := NewTree(data)
+
+	// 测试搜索保留祖先节点
+	result := tree.Search("智能")
+	if ids := result.GetSubCategoryIDs(1, true); !SliceEqual(ids, []uint{1, 2, 3}) {
+		t.Fatalf("期望搜索结果为 [1 2 3]，实际为 %v", ids)
+	}
+	if roots := result.GetRootNodes(); len(roots) != 1 || roots[0].ID != 1 {
+		t.Fatalf("期望搜索结果只有根节点 1，实际为 %v", roots)
+	}
+
+	// 测试结果不与原树共享节点
+	if err := result.RenameNode(3, "Changed"); err != nil || tree.nodes[3].Name != "智能电视" {
+		t.Fatalf("期望修改搜索结果不影响原树")
+	}
+	if len(tree.nodes[2].Children) != 2 {
+		t.Fatalf("期望原树的子节点不受影响，实际为 %v", tree.nodes[2].Children)
+	}
+
+	// 测试自定义条件
+	result = tree.Filter(func(node *TreeNode) bool { return node.ID == 5 || node.ID == 6 })
+	if roots := result.GetRootNodes(); len(roots) != 2 || len(roots[0].Children) != 1 {
+		t.Fatalf("期望过滤结果有 2 个根节点，实际为 %v", roots)
+	}
+}
+
+func TestTreeExport(t *testing.T) {
+	data := []TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 4, ParentID: 1, Name: "Child1", Sorted: 1},
+		{ID: 9, ParentID: 4, Name: "Child1.1"},
+		{ID: 5, ParentID: 1, Name: "Child2", Sorted: 2},
+	}
+	tree, _ := NewTree(data)
+
+	// 测试嵌套集合
+	nestedSet := tree.NestedSet()
+	expectedSet := []NestedSetRow{
+		{ID: 1, Name: "Root", Lft: 1, Rgt: 8, Depth: 0},
+		{ID: 4, Name: "Child1", Sorted: 1, Lft: 2, Rgt: 5, Depth: 1},
+		{ID: 9, Name: "Child1.1", Lft: 3, Rgt: 4, Depth: 2},
+		{ID: 5, Name: "Child2", Sorted: 2, Lft: 6, Rgt: 7, Depth: 1},
+	}
+	if !SliceEqual(nestedSet, expectedSet) {
+		t.Fatalf("期望嵌套集合为 %v，实际为 %v", expectedSet, nestedSet)
+	}
+
+	// 测试物化路径
+	paths := tree.MaterializedPaths()
+	if paths[2].Path != "/1/4/9/" {
+		t.Fatalf("期望节点 9 的路径为 /1/4/9/，实际为 %v", paths[2].Path)
+	}
+
+	// 测试闭包表
+	closure := tree.ClosureTable()
+	if len(closure) != 8 || !SliceContains(closure, ClosureRow{Ancestor: 1, Descendant: 9, Depth: 2}) {
+		t.Fatalf("闭包表内容错误: %v", closure)
+	}
+
+	// 测试从三种结构重建
+	fromSet, err := NewTreeFromNestedSet(nestedSet)
+	if err != nil || !SliceEqual(fromSet.NestedSet(), nestedSet) {
+		t.Fatalf("从嵌套集合重建失败: %v", err)
+	}
+	fromPaths, err := NewTreeFromMaterializedPaths(paths)
+	if err != nil || !SliceEqual(fromPaths.NestedSet(), nestedSet) {
+		t.Fatalf("从物化路径重建失败: %v", err)
+	}
+	nodes := []TreeNode{{ID: 1, Name: "Root"}, {ID: 4, Name: "Child1", Sorted: 1}, {ID: 9, Name: "Child1.1"}, {ID: 5, Name: "Child2", Sorted: 2}}
+	fromClosure, err := NewTreeFromClosureTable(nodes, closure)
+	if err != nil || !SliceEqual(fromClosure.NestedSet(), nestedSet) {
+		t.Fatalf("从闭包表重建失败: %v", err)
+	}
+
+	// 测试无效输入
+	if _, err := NewTreeFromMaterializedPaths([]PathRow{{ID: 2, Path: "/1/3/"}}); err == nil {
+		t.Fatalf("期望路径未以自身 ID 结尾时返回错误")
+	}
+	if _, err := NewTreeFromNestedSet([]NestedSetRow{{ID: 1, Lft: 1, Rgt: 4}, {ID: 2, Lft: 2, Rgt: 6}}); err == nil {
+		t.Fatalf("期望左右值区间交叉时返回错误")
+	}
+	inconsistent := []PathRow{{ID: 1, Path: "/1/"}, {ID: 2, Path: "/2/"}, {ID: 4, Path: "/2/4/"}, {ID: 9, Path: "/1/4/9/"}}
+	if _, err := NewTreeFromMaterializedPaths(inconsistent); err == nil {
+		t.Fatalf("期望路径与父节点路径不一致时返回错误")
+	}
+	if _, err := NewTreeFromNestedSet([]NestedSetRow{{ID: 1, Lft: 1, Rgt: 4}, {ID: 2, Lft: 1, Rgt: 2}}); err == nil {
+		t.Fatalf("期望左右值重复时返回错误")
+	}
+}
+
+func TestTreeIterators(t *testing.T) {
+	data := []TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 2, ParentID: 1, Name: "Child1", Sorted: 1},
+		{ID: 3, ParentID: 1, Name: "Child2", Sorted: 2},
+		{ID: 4, ParentID: 2, Name: "Child1.1"},
+		{ID: 5, ParentID: 3, Name: "Child2.1"},
+		{ID: 6, ParentID: 0, Name: "Root2", Sorted: 1},
+	}
+	tree, _ := NewTree(data)
+	collect := func(seq iter.Seq[*TreeNode]) []uint {
+		var ids []uint
+		for node := range seq {
+			ids = append(ids, node.ID)
+		}
+		return ids
+	}
+
+	// 测试各种遍历顺序
+	if ids := collect(tree.PreOrder(0)); !SliceEqual(ids, []uint{1, 2, 4, 3, 5, 6}) {
+		t.Fatalf("期望先序遍历为 [1 2 4 3 5 6]，实际为 %v", ids)
+	}
+	if ids := collect(tree.PostOrder(1)); !SliceEqual(ids, []uint{4, 2, 5, 3, 1}) {
+		t.Fatalf("期望后序遍历为 [4 2 5 3 1]，实际为 %v", ids)
+	}
+	if ids := collect(tree.BreadthFirst(1)); !SliceEqual(ids, []uint{1, 2, 3, 4, 5}) {
+		t.Fatalf("期望广度优先遍历为 [1 2 3 4 5]，实际为 %v", ids)
+	}
+	for depth, level := range tree.Levels(0) {
+		if depth == 1 && len(level) != 2 {
+			t.Fatalf("期望第 1 层有 2 个节点，实际为 %v", level)
+		}
+	}
+
+	// 测试最大深度
+	if ids := collect(tree.PreOrder(1, WithMaxDepth(1))); !SliceEqual(ids, []uint{1, 2, 3}) {
+		t.Fatalf("期望限制深度后为 [1 2 3]，实际为 %v", ids)
+	}
+	if ids := collect(tree.PostOrder(1, WithMaxDepth(0))); !SliceEqual(ids, []uint{1}) {
+		t.Fatalf("期望限制深度后为 [1]，实际为 %v", ids)
+	}
+
+	// 测试提前终止
+	var visited []uint
+	for node := range tree.BreadthFirst(0) {
+		visited = append(visited, node.ID)
+		if node.ID == 2 {
+			break
+		}
+	}
+	if !SliceEqual(visited, []uint{1, 6, 2}) {
+		t.Fatalf("期望提前终止于节点 2，实际为 %v", visited)
+	}
+	if ids := collect(tree.PreOrder(99)); len(ids) != 0 {
+		t.Fatalf("期望不存在的起始节点不返回任何节点，实际为 %v", ids)
+	}
+
+	// 测试遍历是惰性的：break 后不再展开剩余节点，循环体内新增的子节点会被遍历到
+	large := newBenchmarkTree(50000)
+	for name, seq := range map[string]iter.Seq[*TreeNode]{
+		"PreOrder":     large.PreOrder(1),
+		"PostOrder":    large.PostOrder(1),
+		"BreadthFirst": large.BreadthFirst(1),
+	} {
+		allocs := testing.AllocsPerRun(10, func() {
+			for range seq {
+				break
+			}
+		})
+		if allocs > 50 {
+			t.Fatalf("期望 %s 提前终止时不遍历整棵树，实际分配 %v 次", name, allocs)
+		}
+	}
+	var added bool
+	visited = nil
+	for node := range tree.PreOrder(6) {
+		visited = append(visited, node.ID)
+		if !added {
+			added = true
+			_ = tree.AddNode(TreeNode{ID: 7, ParentID: 6, Name: "Root2.1"})
+		}
+	}
+	if !SliceEqual(visited, []uint{6, 7}) {
+		t.Fatalf("期望遍历到循环体内新增的节点 7，实际为 %v", visited)
+	}
+	_ = tree.DeleteNode(7)
+
+	// 测试循环体内调用 Tree 的方法，且有写操作等待时不会死锁
+	done := make(chan []string)
+	go func() {
+		var paths []string
+		for node := range tree.PreOrder(1) {
+			if node.ID == 1 {
+				go tree.RenameNode(6, "Root2'")
+				time.Sleep(10 * time.Millisecond)
+			}
+			paths = append(paths, tree.PathName(node.ID, "/"))
+		}
+		done <- paths
+	}()
+	select {
+	case paths := <-done:
+		if len(paths) != 5 || paths[2] != "Root/Child1/Child1.1" {
+			t.Fatalf("期望获取 5 个节点路径，实际为 %v", paths)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("遍历期间调用 Tree 的方法发生死锁")
+	}
+}
+
+func TestTreeDiff(t *testing.T) {
+	oldTree, _ := NewTree([]TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 2, ParentID: 1, Name: "A"},
+		{ID: 3, ParentID: 2, Name: "B"},
+		{ID: 4, ParentID: 1, Name: "C", Sorted: 1},
+		{ID: 5, ParentID: 4, Name: "D"},
+	})
+	newTree, _ := NewTree([]TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 3, ParentID: 0, Name: "B"},
+		{ID: 2, ParentID: 3, Name: "A2"},
+		{ID: 4, ParentID: 1, Name: "C", Sorted: 5},
+		{ID: 6, ParentID: 2, Name: "E"},
+	})
+
+	// 测试差异计算
+	diff := DiffTree(oldTree, newTree)
+	if len(diff.Added) != 1 || diff.Added[0].ID != 6 {
+		t.Fatalf("期望新增节点 6，实际为 %v", diff.Added)
+	}
+	if !SliceEqual(diff.Removed, []uint{5}) {
+		t.Fatalf("期望删除节点 [5]，实际为 %v", diff.Removed)
+	}
+	expectedMoves := []TreeMove{{ID: 3, From: 2, To: 0}, {ID: 2, From: 1, To: 3}}
+	if !SliceEqual(diff.Moved, expectedMoves) {
+		t.Fatalf("期望移动为 %v，实际为 %v", expectedMoves, diff.Moved)
+	}
+	if len(diff.Renamed) != 1 || diff.Renamed[0] != (TreeRename{ID: 2, From: "A", To: "A2"}) {
+		t.Fatalf("期望重命名节点 2，实际为 %v", diff.Renamed)
+	}
+	if len(diff.Reordered) != 1 || diff.Reordered[0] != (TreeReorder{ID: 4, From: 1, To: 5}) {
+		t.Fatalf("期望节点 4 排序变更，实际为 %v", diff.Reordered)
+	}
+
+	// 测试应用差异后与新树一致
+	if err := oldTree.ApplyDiff(diff); err != nil {
+		t.Fatalf("应用差异失败: %v", err)
+	}
+	if again := DiffTree(oldTree, newTree); !again.IsEmpty() {
+		t.Fatalf("期望应用差异后没有差异，实际为 %+v", again)
+	}
+	if !SliceEqual(oldTree.NestedSet(), newTree.NestedSet()) {
+		t.Fatalf("期望应用差异后树结构一致")
+	}
+
+	// 测试双向并发比较且有写操作时不会死锁
+	done := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 200; j++ {
+					switch i {
+					case 0:
+						DiffTree(oldTree, newTree)
+					case 1:
+						DiffTree(newTree, oldTree)
+					case 2:
+						_ = oldTree.RenameNode(1, fmt.Sprint("Root", j))
+					case 3:
+						_ = newTree.RenameNode(1, fmt.Sprint("Root", j))
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("双向并发比较发生死锁")
+	}
+}
+
+func TestTreeJSON(t *testing.T) {
+	input := `[
+		{"id": 1, "name": "Root", "children": [
+			{"id": 3, "name": "Child2", "sorted": 1, "children": [{"id": 5, "name": "Child2.1"}]},
+			{"id": 2, "name": "Child1", "sorted": 2}
+		]},
+		{"id": 6, "name": "Root2", "sorted": 1}
+	]`
+
+	// 测试从嵌套 JSON 构建
+	tree, err := NewTreeFromJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("解析嵌套 JSON 失败: %v", err)
+	}
+	if !tree.IsParent(5, 3) || !tree.IsParent(3, 1) || tree.TreeLevel(5) != 2 {
+		t.Fatalf("期望根据嵌套关系推断 ParentID")
+	}
+
+	// 测试展开为扁平列表
+	flat := tree.Flatten()
+	expected := []TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 3, ParentID: 1, Name: "Child2", Sorted: 1},
+		{ID: 5, ParentID: 3, Name: "Child2.1"},
+		{ID: 2, ParentID: 1, Name: "Child1", Sorted: 2},
+		{ID: 6, ParentID: 0, Name: "Root2", Sorted: 1},
+	}
+	if len(flat) != len(expected) {
+		t.Fatalf("期望展开后有 %d 个节点，实际为 %v", len(expected), flat)
+	}
+	for i := range flat {
+		if flat[i].ID != expected[i].ID || flat[i].ParentID != expected[i].ParentID || flat[i].Children != nil {
+			t.Fatalf("期望第 %d 个节点为 %v，实际为 %v", i, expected[i], flat[i])
+		}
+	}
+
+	// 测试序列化往返
+	encoded, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatalf("序列化树失败: %v", err)
+	}
+	var decoded Tree
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("反序列化树失败: %v", err)
+	}
+	if !DiffTree(tree, &decoded).IsEmpty() {
+		t.Fatalf("期望序列化往返后树结构一致")
+	}
+
+	// 测试重复 ID
+	_, err = NewTreeFromJSON([]byte(`{"id": 1, "children": [{"id": 2, "children": [{"id": 1}]}]}`))
+	var treeErr *TreeError
+	if !errors.As(err, &treeErr) || !treeErr.Has(TreeIssueDuplicateID) {
+		t.Fatalf("期望重复 ID 返回校验错误，实际为 %v", err)
+	}
+}
+
+func TestTreeStats(t *testing.T) {
+	data := []TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 2, ParentID: 1, Name: "Child1"},
+		{ID: 3, ParentID: 1, Name: "Child2"},
+		{ID: 4, ParentID: 2, Name: "Child1.1"},
+		{ID: 5, ParentID: 4, Name: "Child1.1.1"},
+	}
+	tree, _ := NewTree(data)
+
+	// 测试子树统计
+	stats, found := tree.Stats(1)
+	if !found || stats != (TreeStats{DescendantCount: 4, LeafCount: 2, Height: 3}) {
+		t.Fatalf("节点 1 的统计信息错误: %+v", stats)
+	}
+	if stats, _ := tree.Stats(3); stats != (TreeStats{LeafCount: 1}) {
+		t.Fatalf("节点 3 的统计信息错误: %+v", stats)
+	}
+	if _, found := tree.Stats(99); found {
+		t.Fatalf("期望不存在的节点没有统计信息")
+	}
+
+	// 测试指标合计
+	tree.SetMetric("products", map[uint]float64{2: 1, 3: 2, 5: 10})
+	if total := tree.MetricTotal("products", 1); total != 13 {
+		t.Fatalf("期望节点 1 的商品合计为 13，实际为 %v", total)
+	}
+	if total := tree.MetricTotal("products", 2); total != 11 {
+		t.Fatalf("期望节点 2 的商品合计为 11，实际为 %v", total)
+	}
+
+	// 测试变更后缓存失效
+	_ = tree.MoveNode(4, 3)
+	if stats, _ := tree.Stats(2); stats != (TreeStats{LeafCount: 1}) {
+		t.Fatalf("期望移动后节点 2 的统计信息更新，实际为 %+v", stats)
+	}
+	if total := tree.MetricTotal("products", 3); total != 12 {
+		t.Fatalf("期望移动后节点 3 的商品合计为 12，实际为 %v", total)
+	}
+	values := map[uint]float64{1: 1}
+	tree.SetMetric("products", values)
+	if total := tree.MetricTotal("products", 3); total != 0 {
+		t.Fatalf("期望重新设置指标后合计更新，实际为 %v", total)
+	}
+
+	// 测试修改传入的指标不影响已设置的指标
+	values[3] = 100
+	if total := tree.MetricTotal("products", 1); total != 1 {
+		t.Fatalf("期望修改传入的指标后合计不变，实际为 %v", total)
+	}
+}
+
+func TestTreeRender(t *testing.T) {
+	data := []TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 2, ParentID: 1, Name: "Child1", Sorted: 1},
+		{ID: 3, ParentID: 1, Name: "Child2", Sorted: 2},
+		{ID: 4, ParentID: 2, Name: "Child1.1"},
+	}
+	tree, _ := NewTree(data)
+
+	// 测试文本大纲
+	expected := "1 Root\n├── 2 Child1\n│   └── 4 Child1.1\n└── 3 Child2\n"
+	if text := tree.RenderText(); text != expected {
+		t.Fatalf("期望文本大纲为\n%s实际为\n%s", expected, text)
+	}
+	expected = "Root\n|-- Child1\n`-- Child2\n"
+	if text := tree.RenderText(WithRenderFields(TreeFieldName), WithRenderMaxDepth(1), WithRenderASCII()); text != expected {
+		t.Fatalf("期望文本大纲为\n%s实际为\n%s", expected, text)
+	}
+
+	// 测试 DOT
+	dot := tree.RenderDOT(WithRenderLabel(func(node *TreeNode) string { return `"` + node.Name + `"` }))
+	if !strings.Contains(dot, `n4 [label="\"Child1.1\""];`) || !strings.Contains(dot, "n2 -> n4;") {
+		t.Fatalf("DOT 输出错误:\n%s", dot)
+	}
+
+	// 测试 Mermaid
+	mermaid := tree.RenderMermaid(WithRenderMaxDepth(1))
+	if !strings.HasPrefix(mermaid, "graph TD\n") || !strings.Contains(mermaid, "n1 --> n3") || strings.Contains(mermaid, "n4") {
+		t.Fatalf("Mermaid 输出错误:\n%s", mermaid)
+	}
+}
+
+func TestTreeSnapshot(t *testing.T) {
+	data := []TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 2, ParentID: 1, Name: "Child1"},
+		{ID: 3, ParentID: 2, Name: "Child1.1"},
+	}
+	tree, _ := NewTree(data)
+	snapshot := tree.Snapshot()
+
+	// 测试修改原树不影响快照
+	_ = tree.RenameNode(2, "Changed")
+	_ = tree.DeleteNode(3)
+	if node, _ := snapshot.Node(2); node.Name != "Child1" || snapshot.Len() != 3 {
+		t.Fatalf("期望快照不受原树修改影响，实际为 %v", node)
+	}
+
+	// 测试修改返回值不影响快照
+	subTree, _ := snapshot.SubTree(1)
+	subTree.Children[0].Name = "Mutated"
+	if children := snapshot.Children(1); children[0].Name != "Child1" {
+		t.Fatalf("期望修改返回值不影响快照，实际为 %v", children)
+	}
+	if path := snapshot.PathName(3, "/"); path != "Root/Child1/Child1.1" {
+		t.Fatalf("期望快照路径为 Root/Child1/Child1.1，实际为 %v", path)
+	}
+
+	// 测试原子替换
+	holder := NewAtomicTree(tree)
+	before := holder.Load()
+	loaded := []TreeNode{{ID: 1, Name: "Root"}, {ID: 9, ParentID: 1, Name: "New"}}
+	err := holder.Reload(func() ([]TreeNode, error) {
+		return loaded, nil
+	})
+	if err != nil || !holder.Load().IsParent(9, 1) || before.Len() != 2 {
+		t.Fatalf("期望重新加载后替换为新快照且旧快照不变: %v", err)
+	}
+	loaded[0].Name = "Changed"
+	if node, _ := holder.Load().Node(1); node.Name != "Root" {
+		t.Fatalf("期望修改加载的数据不影响快照，实际为 %v", node.Name)
+	}
+	err = holder.Reload(func() ([]TreeNode, error) {
+		return []TreeNode{{ID: 1, ParentID: 1}}, nil
+	})
+	if err == nil || !holder.Load().IsParent(9, 1) {
+		t.Fatalf("期望重新加载失败时保留旧快照")
+	}
+}
+
+func TestTreeRootRules(t *testing.T) {
+	// 测试自引用表示根节点，ID 为 0 的节点可以存在
+	tree, err := NewTree([]TreeNode{
+		{ID: 0, ParentID: 0, Name: "Root0"},
+		{ID: 1, ParentID: 0, Name: "Child"},
+		{ID: 2, ParentID: 2, Name: "Root2"},
+	}, WithRootRule(RootBySelfParent()))
+	if err != nil {
+		t.Fatalf("创建树失败: %v", err)
+	}
+	if roots := tree.GetRootNodes(); len(roots) != 2 || roots[0].ID != 0 || roots[1].ID != 2 {
+		t.Fatalf("期望根节点为 [0 2]，实际为 %v", roots)
+	}
+	if tree.TreeLevel(1) != 1 || !tree.IsParent(1, 0) || tree.IsParent(2, 2) {
+		t.Fatalf("自引用规则下的父子关系错误")
+	}
+	if err := tree.MoveNode(1, 1); err != nil || tree.TreeLevel(1) != 0 {
+		t.Fatalf("期望 MoveNode(1, 1) 将节点 1 移动为根节点: %v", err)
+	}
+
+	// 测试指定标记值，标记值不能作为节点 ID
+	tree, err = NewTree([]TreeNode{
+		{ID: 1, ParentID: 100, Name: "Root"},
+		{ID: 2, ParentID: 1, Name: "Child"},
+	}, WithRootRule(RootBySentinel(100)))
+	if err != nil || tree.TreeLevel(2) != 1 {
+		t.Fatalf("指定标记值规则错误: %v", err)
+	}
+	if err := tree.AddNode(TreeNode{ID: 100, ParentID: 1}); err == nil {
+		t.Fatalf("期望使用标记值作为节点 ID 时返回错误")
+	}
+	_, err = NewTree([]TreeNode{{ID: 0, ParentID: 0}})
+	var treeErr *TreeError
+	if !errors.As(err, &treeErr) || !treeErr.Has(TreeIssueReservedID) {
+		t.Fatalf("期望默认规则下 ID 为 0 的节点返回校验错误，实际为 %v", err)
+	}
+
+	// 测试父节点不存在即为根节点
+	tree, err = NewTree([]TreeNode{
+		{ID: 1, ParentID: 99, Name: "RootA"},
+		{ID: 2, ParentID: 1, Name: "Child"},
+		{ID: 3, ParentID: 98, Name: "RootB"},
+	}, WithRootRule(RootByMissingParent()))
+	if err != nil || len(tree.GetRootNodes()) != 2 {
+		t.Fatalf("父节点不存在规则错误: %v", err)
+	}
+
+	// 测试拆分森林
+	forest := tree.SplitForest()
+	if len(forest) != 2 || len(forest[0].nodes) != 2 || len(forest[1].nodes) != 1 {
+		t.Fatalf("期望拆分为 2 棵树，实际为 %v", forest)
+	}
+	_ = forest[0].RenameNode(2, "Changed")
+	if tree.nodes[2].Name != "Child" {
+		t.Fatalf("期望拆分后的树不与原树共享节点")
+	}
+
+	// 测试挂载虚拟根节点
+	if err := tree.AttachVirtualRoot(TreeNode{ID: 10, Name: "Virtual"}); err != nil {
+		t.Fatalf("挂载虚拟根节点失败: %v", err)
+	}
+	if roots := tree.GetRootNodes(); len(roots) != 1 || roots[0].ID != 10 || tree.TreeLevel(2) != 2 {
+		t.Fatalf("期望只有虚拟根节点 10，实际为 %v", roots)
+	}
+
+	// 测试新增节点收养 ParentID 指向它的根节点
+	tree, _ = NewTree([]TreeNode{
+		{ID: 1, ParentID: 0, Name: "Root"},
+		{ID: 2, ParentID: 1, Name: "Child"},
+	}, WithRootRule(RootByMissingParent()))
+	if err := tree.AddNode(TreeNode{ID: 0, ParentID: 99, Name: "Root0"}); err != nil {
+		t.Fatalf("新增节点 0 失败: %v", err)
+	}
+	if ids := tree.GetSubCategoryIDs(0, true); !SliceEqual(ids, []uint{0, 1, 2}) || tree.TreeLevel(1) != 1 {
+		t.Fatalf("期望节点 0 的子树为 [0 1 2]，实际为 %v", ids)
+	}
+	if err := tree.AddNode(TreeNode{ID: 99, ParentID: 2}); !errors.Is(err, ErrTreeCycle) {
+		t.Fatalf("期望新增节点会形成环时返回 ErrTreeCycle，实际为 %v", err)
+	}
+
+	// 测试删除根节点后子节点的 ParentID 不指向已存在的节点
+	if err := tree.DeleteNodeKeepChildren(0); err != nil {
+		t.Fatalf("删除节点失败: %v", err)
+	}
+	if _, found := tree.nodes[tree.nodes[1].ParentID]; found || tree.TreeLevel(2) != 1 {
+		t.Fatalf("期望节点 1 成为根节点，实际 ParentID 为 %v", tree.nodes[1].ParentID)
+	}
+	if err := tree.AddNode(TreeNode{ID: 0, ParentID: 99, Name: "Root0"}); err != nil || tree.TreeLevel(2) != 1 {
+		t.Fatalf("期望重新新增节点 0 后节点 1 仍为根节点: %v", err)
+	}
+
+	// 测试删除根节点后提升的子节点不会被之后新增的节点收养
+	tree, _ = NewTree([]TreeNode{
+		{ID: 10, ParentID: 99, Name: "Root"},
+		{ID: 1, ParentID: 10, Name: "Child1"},
+		{ID: 2, ParentID: 10, Name: "Child2"},
+	}, WithRootRule(RootByMissingParent()))
+	_ = tree.DeleteNodeKeepChildren(10)
+	if err := tree.AddNode(TreeNode{ID: 0, ParentID: 500, Name: "Other"}); err != nil {
+		t.Fatalf("新增节点 0 失败: %v", err)
+	}
+	if roots := tree.GetRootNodes(); len(roots) != 3 || tree.TreeLevel(1) != 0 || tree.TreeLevel(2) != 0 {
+		t.Fatalf("期望节点 0、1、2 均为根节点，实际为 %v", roots)
+	}
+	if _, found := tree.nodes[tree.nodes[1].ParentID]; found {
+		t.Fatalf("期望节点 1 的 ParentID 仍指向不存在的节点，实际为 %d", tree.nodes[1].ParentID)
+	}
+	if err := tree.AddNode(TreeNode{ID: 3, ParentID: 1}); err != nil || tree.TreeLevel(3) != 1 {
+		t.Fatalf("期望提升的子节点仍可以正常新增子节点: %v", err)
+	}
+
+	// 测试导入时顶层节点不会挂到 ID 为 0 的节点下
+	nested, err := NewTreeFromNested([]TreeNode{
+		{ID: 1, Name: "Root", Children: []*TreeNode{{ID: 0, Name: "Child0"}}},
+	}, WithRootRule(RootByMissingParent()))
+	if err != nil || len(nested.GetRootNodes()) != 1 || nested.TreeLevel(0) != 1 {
+		t.Fatalf("期望节点 1 为唯一根节点: %v", err)
+	}
+	missing := nested.nodes[1].ParentID
+	if err := nested.AddNode(TreeNode{ID: missing, ParentID: 500}); err != nil || nested.TreeLevel(1) != 0 {
+		t.Fatalf("期望导入的顶层节点不会被新增节点 %d 收养: %v", missing, err)
+	}
+	if clone := nested.Clone(); !clone.isAssigned(1) {
+		t.Fatalf("期望克隆后保留自动设置 ParentID 的根节点")
+	}
+
+	// 测试虚拟根节点不会被之后新增的节点收养
+	_ = nested.AttachVirtualRoot(TreeNode{ID: 100, Name: "Virtual"})
+	missing = nested.nodes[100].ParentID
+	if err := nested.AddNode(TreeNode{ID: missing, ParentID: 500}); err != nil || nested.TreeLevel(100) != 0 {
+		t.Fatalf("期望虚拟根节点不会被新增节点 %d 收养: %v", missing, err)
+	}
+
+	// 测试移动为根节点后按用户指定的 ParentID 收养
+	_ = nested.MoveNode(1, 700)
+	if err := nested.AddNode(TreeNode{ID: 700, ParentID: 600}); err != nil || nested.TreeLevel(1) != 1 {
+		t.Fatalf("期望节点 1 被新增节点 700 收养: %v", err)
+	}
+}
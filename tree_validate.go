@@ -0,0 +1,182 @@
+package ji
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TreeIssueKind 树结构校验问题的类型
+type TreeIssueKind int
+
+// 树结构校验问题类型
+const (
+	TreeIssueDuplicateID TreeIssueKind = iota + 1 // 节点 ID 重复
+	TreeIssueSelfParent                           // 节点的 ParentID 指向自身
+	TreeIssueCycle                                // 父子关系中存在环
+	TreeIssueOrphan                               // ParentID 指向不存在的节点
+	TreeIssueReservedID                           // ID 与根节点标记值冲突
+)
+
+// String 返回问题类型的描述
+func (k TreeIssueKind) String() string {
+	switch k {
+	case TreeIssueDuplicateID:
+		return "ID 重复"
+	case TreeIssueSelfParent:
+		return "父节点指向自身"
+	case TreeIssueCycle:
+		return "父子关系存在环"
+	case TreeIssueOrphan:
+		return "父节点不存在"
+	case TreeIssueReservedID:
+		return "ID 与根节点标记值冲突"
+	default:
+		return "未知问题"
+	}
+}
+
+// TreeIssue 描述单个节点的校验问题
+type TreeIssue struct {
+	ID   uint          `json:"id"`   // 出现问题的节点 ID
+	Kind TreeIssueKind `json:"kind"` // 问题类型
+}
+
+// TreeError 树结构校验错误，列出所有出问题的节点
+type TreeError struct {
+	Issues []TreeIssue `json:"issues"`
+}
+
+// Error 实现 error 接口
+func (e *TreeError) Error() string {
+	var builder strings.Builder
+	builder.WriteString("树结构校验失败: ")
+	for i, issue := range e.Issues {
+		if i > 0 {
+			builder.WriteString("; ")
+		}
+		builder.WriteString(fmt.Sprintf("节点 %d %s", issue.ID, issue.Kind))
+	}
+	return builder.String()
+}
+
+// Has 判断是否包含指定类型的问题
+func (e *TreeError) Has(kind TreeIssueKind) bool {
+	for _, issue := range e.Issues {
+		if issue.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// TreeOption NewTree 的可选配置
+type TreeOption func(*treeConfig)
+
+// treeConfig 树的构建配置
+type treeConfig struct {
+	orphansAsRoots bool            // 将父节点不存在的节点视为根节点
+	compare        TreeCompareFunc // 兄弟节点排序规则
+	rootRule       TreeRootRule    // 根节点判定规则
+}
+
+// newTreeConfig 根据选项生成树的配置
+func newTreeConfig(opts []TreeOption) treeConfig {
+	var config treeConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// WithOrphansAsRoots 容忍孤儿节点：父节点不存在的节点会按根节点规则重置 ParentID（默认置为 0），作为根节点处理
+func WithOrphansAsRoots() TreeOption {
+	return func(c *treeConfig) {
+		c.orphansAsRoots = true
+	}
+}
+
+// validate 检查节点映射中的孤儿节点与环，data 为原始输入顺序（保证问题列表顺序稳定）
+func (tree *Tree) validate(data []*TreeNode) []TreeIssue {
+	var issues []TreeIssue
+
+	// 检查保留 ID、自引用与孤儿节点
+	for _, node := range data {
+		if tree.config.reservedID(node.ID) {
+			issues = append(issues, TreeIssue{ID: node.ID, Kind: TreeIssueReservedID})
+		}
+		if tree.isRoot(node) {
+			continue
+		}
+		if node.ParentID == node.ID {
+			issues = append(issues, TreeIssue{ID: node.ID, Kind: TreeIssueSelfParent})
+			continue
+		}
+		if _, found := tree.nodes[node.ParentID]; !found {
+			if tree.config.orphansAsRoots {
+				node.ParentID = tree.config.rootParentID(node.ID)
+				continue
+			}
+			issues = append(issues, TreeIssue{ID: node.ID, Kind: TreeIssueOrphan})
+		}
+	}
+
+	// 查找环
+	ids := make([]uint, len(data))
+	for i, node := range data {
+		ids[i] = node.ID
+	}
+	cycles := findCycles(ids, func(id uint) (uint, bool) {
+		node := tree.nodes[id]
+		if tree.isRoot(node) || node.ParentID == node.ID {
+			return 0, false
+		}
+		_, found := tree.nodes[node.ParentID]
+		return node.ParentID, found
+	})
+	for _, cycle := range cycles {
+		for _, id := range cycle {
+			issues = append(issues, TreeIssue{ID: id, Kind: TreeIssueCycle})
+		}
+	}
+	return issues
+}
+
+// findCycles 沿父节点向上查找所有环，返回每个环上的节点 ID
+// parentOf 返回节点的父节点 ID，第二个返回值为 false 表示已到达根节点（或父节点不存在）
+func findCycles[K comparable](ids []K, parentOf func(K) (K, bool)) [][]K {
+	var cycles [][]K
+
+	// 0 未访问，1 当前路径上，2 已确认无环
+	state := make(map[K]uint8, len(ids))
+	for _, id := range ids {
+		var path []K
+		cycleStart := -1
+		current := id
+		for {
+			if state[current] == 2 {
+				break
+			}
+			if state[current] == 1 {
+				// 回到当前路径上的节点，说明找到环
+				cycleStart = slices.Index(path, current)
+				break
+			}
+			state[current] = 1
+			path = append(path, current)
+			parent, ok := parentOf(current)
+			if !ok {
+				break
+			}
+			current = parent
+		}
+
+		if cycleStart >= 0 {
+			cycles = append(cycles, path[cycleStart:])
+		}
+		for _, visited := range path {
+			state[visited] = 2
+		}
+	}
+	return cycles
+}